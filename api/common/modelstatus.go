@@ -8,6 +8,7 @@ import (
 	"github.com/juju/names/v4"
 
 	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/core/instance"
 	"github.com/juju/juju/core/model"
 	"github.com/juju/juju/rpc/params"
 )
@@ -114,8 +115,27 @@ func constructModelStatus(m names.ModelTag, owner names.UserTag, r params.ModelS
 			WantsVote:   mm.WantsVote,
 			Status:      mm.Status,
 			Message:     mm.Message,
+			Hardware:    hardwareCharacteristics(mm.Hardware),
 			HAPrimary:   mm.HAPrimary,
 		}
 	}
 	return result
 }
+
+// hardwareCharacteristics converts the wire representation of a machine's
+// hardware info into the client-facing type, returning nil if none was
+// reported (e.g. by an older controller, or for a container).
+func hardwareCharacteristics(hw *params.MachineHardware) *instance.HardwareCharacteristics {
+	if hw == nil {
+		return nil
+	}
+	return &instance.HardwareCharacteristics{
+		Arch:             hw.Arch,
+		Mem:              hw.Mem,
+		RootDisk:         hw.RootDisk,
+		CpuCores:         hw.Cores,
+		CpuPower:         hw.CpuPower,
+		Tags:             hw.Tags,
+		AvailabilityZone: hw.AvailabilityZone,
+	}
+}
@@ -218,6 +218,57 @@ func (s *SecretsManagerSuite) TestCreateSecrets(c *gc.C) {
 	})
 }
 
+func (s *SecretsManagerSuite) TestCreateSecretsEphemeral(c *gc.C) {
+	defer s.setup(c).Finish()
+
+	p := state.CreateSecretParams{
+		Version:   secrets.Version,
+		Owner:     names.NewApplicationTag("mariadb"),
+		Ephemeral: true,
+		UpdateSecretParams: state.UpdateSecretParams{
+			LeaderToken: s.token,
+			Data:        map[string]string{"foo": "bar"},
+		},
+	}
+	var gotURI *coresecrets.URI
+	s.leadership.EXPECT().LeadershipCheck("mariadb", "mariadb/0").Return(s.token)
+	s.token.EXPECT().Check().Return(nil)
+	s.secretsBackend.EXPECT().CreateSecret(gomock.Any(), p).DoAndReturn(
+		func(uri *coresecrets.URI, p state.CreateSecretParams) (*coresecrets.SecretMetadata, error) {
+			ownerTag := names.NewApplicationTag("mariadb")
+			s.secretsConsumer.EXPECT().GrantSecretAccess(uri, state.SecretAccessParams{
+				LeaderToken: s.token,
+				Scope:       ownerTag,
+				Subject:     ownerTag,
+				Role:        coresecrets.RoleManage,
+			}).Return(nil)
+			gotURI = uri
+			md := &coresecrets.SecretMetadata{
+				URI:            uri,
+				Ephemeral:      true,
+				LatestRevision: 1,
+			}
+			return md, nil
+		},
+	)
+
+	results, err := s.facade.CreateSecrets(params.CreateSecretArgs{
+		Args: []params.CreateSecretArg{{
+			OwnerTag:  "application-mariadb",
+			Ephemeral: true,
+			UpsertSecretArg: params.UpsertSecretArg{
+				Content: params.SecretContentParams{Data: map[string]string{"foo": "bar"}},
+			},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.StringResults{
+		Results: []params.StringResult{{
+			Result: gotURI.String(),
+		}},
+	})
+}
+
 func (s *SecretsManagerSuite) TestCreateSecretDuplicateLabel(c *gc.C) {
 	defer s.setup(c).Finish()
 
@@ -0,0 +1,64 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cert_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	utilscert "github.com/juju/utils/v3/cert"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/testing"
+)
+
+type BundleSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&BundleSuite{})
+
+// newServerBundle builds a CA-signed server certificate and key directly
+// with utilscert.NewLeaf, independently of cert.NewServer.
+func newServerBundle(c *gc.C) cert.Bundle {
+	caCertPEM, caKeyPEM, err := utilscert.NewCA("testing", "some-uuid", time.Now().Add(time.Hour), 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	certPEM, keyPEM, err := utilscert.NewLeaf(&utilscert.Config{
+		CommonName: "server",
+		UUID:       "some-uuid",
+		Expiry:     time.Now().Add(time.Hour),
+		CA:         []byte(caCertPEM),
+		CAKey:      []byte(caKeyPEM),
+		Hostnames:  []string{"localhost"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return cert.Bundle{CertPEM: certPEM, KeyPEM: keyPEM}
+}
+
+func (s *BundleSuite) TestParse(c *gc.C) {
+	bundle := newServerBundle(c)
+
+	parsedCert, parsedKey, err := bundle.Parse()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsedCert.Subject.CommonName, gc.Equals, "server")
+	c.Assert(parsedKey, gc.NotNil)
+}
+
+func (s *BundleSuite) TestTLSCertificate(c *gc.C) {
+	bundle := newServerBundle(c)
+
+	tlsCert, err := bundle.TLSCertificate()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tlsCert.Leaf, gc.NotNil)
+	c.Assert(tlsCert.Leaf.Subject.CommonName, gc.Equals, "server")
+	c.Assert(tlsCert.PrivateKey, gc.NotNil)
+}
+
+func (s *BundleSuite) TestParseInvalid(c *gc.C) {
+	bundle := cert.Bundle{CertPEM: "not a cert", KeyPEM: "not a key"}
+	_, _, err := bundle.Parse()
+	c.Assert(err, gc.NotNil)
+}
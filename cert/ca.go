@@ -0,0 +1,85 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cert
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+const caKeyBits = 2048
+
+// CAOptions customizes the x509 template used by NewCAWithOptions beyond
+// what the name/expiry parameters already capture.
+type CAOptions struct {
+	// MaxPathLen caps how many intermediate CA certificates may follow
+	// this one in a certificate chain. A nil value leaves the
+	// constraint unset, matching cert.NewCA's existing behavior.
+	MaxPathLen *int
+
+	// SerialNumber, if set, is used as the certificate's serial number
+	// instead of a randomly generated one, so that tests relying on
+	// golden output or reproducible builds can get the same certificate
+	// bytes every time. A nil value keeps the existing random behavior.
+	SerialNumber *big.Int
+}
+
+// NewCAWithOptions generates a self-signed CA certificate/key pair, much
+// like the upstream cert.NewCA, but additionally lets the caller constrain
+// the certificate's path length via opts so that, for example, a CA can be
+// scoped to never sign intermediates.
+func NewCAWithOptions(commonName, uuid string, expiry time.Time, opts CAOptions) (certPEM, keyPEM string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, caKeyBits)
+	if err != nil {
+		return "", "", errors.Errorf("cannot generate key: %v", err)
+	}
+	serialNumber := opts.SerialNumber
+	if serialNumber == nil {
+		serialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+		if err != nil {
+			return "", "", errors.Trace(err)
+		}
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:   commonName,
+			Organization: []string{"juju"},
+			SerialNumber: uuid,
+		},
+		SerialNumber:          serialNumber,
+		NotBefore:             now.UTC().AddDate(0, 0, -7),
+		NotAfter:              expiry.UTC(),
+		Version:               2,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if opts.MaxPathLen != nil {
+		template.MaxPathLen = *opts.MaxPathLen
+		template.MaxPathLenZero = *opts.MaxPathLen == 0
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return "", "", errors.Trace(err)
+	}
+	certPEMData := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: certDER,
+	})
+	keyPEMData := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+	return string(certPEMData), string(keyPEMData), nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cert_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/testing"
+)
+
+type CASuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&CASuite{})
+
+func (s *CASuite) TestNewCAWithOptionsDefaultMatchesUnconstrained(c *gc.C) {
+	certPEM, keyPEM, err := cert.NewCAWithOptions("testing", "some-uuid", time.Now().Add(time.Hour), cert.CAOptions{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	bundle := cert.Bundle{CertPEM: certPEM, KeyPEM: keyPEM}
+	parsed, _, err := bundle.Parse()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsed.MaxPathLen, gc.Equals, -1)
+	c.Assert(parsed.MaxPathLenZero, jc.IsFalse)
+}
+
+func (s *CASuite) TestNewCAWithOptionsSetsMaxPathLen(c *gc.C) {
+	zero := 0
+	certPEM, keyPEM, err := cert.NewCAWithOptions("testing", "some-uuid", time.Now().Add(time.Hour), cert.CAOptions{
+		MaxPathLen: &zero,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	bundle := cert.Bundle{CertPEM: certPEM, KeyPEM: keyPEM}
+	parsed, _, err := bundle.Parse()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsed.MaxPathLen, gc.Equals, 0)
+	c.Assert(parsed.MaxPathLenZero, jc.IsTrue)
+}
+
+func (s *CASuite) TestNewCAWithOptionsFixedSerialNumber(c *gc.C) {
+	serial := big.NewInt(424242)
+
+	certPEM1, keyPEM1, err := cert.NewCAWithOptions("testing", "some-uuid", time.Now().Add(time.Hour), cert.CAOptions{
+		SerialNumber: serial,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	certPEM2, keyPEM2, err := cert.NewCAWithOptions("testing", "some-uuid", time.Now().Add(time.Hour), cert.CAOptions{
+		SerialNumber: serial,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	parsed1, _, err := (cert.Bundle{CertPEM: certPEM1, KeyPEM: keyPEM1}).Parse()
+	c.Assert(err, jc.ErrorIsNil)
+	parsed2, _, err := (cert.Bundle{CertPEM: certPEM2, KeyPEM: keyPEM2}).Parse()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(parsed1.SerialNumber, jc.DeepEquals, serial)
+	c.Assert(parsed2.SerialNumber, jc.DeepEquals, serial)
+}
+
+// signCA signs a new CA certificate for commonName using parent/parentKey,
+// bypassing the upstream NewLeaf helper, which refuses to chain under a
+// parent when asked for a CA certificate.
+func signCA(c *gc.C, commonName string, parent *x509.Certificate, parentKey *rsa.PrivateKey) (*x509.Certificate, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	c.Assert(err, jc.ErrorIsNil)
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	c.Assert(err, jc.ErrorIsNil)
+
+	now := time.Now()
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		SerialNumber:          serialNumber,
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	c.Assert(err, jc.ErrorIsNil)
+	signed, err := x509.ParseCertificate(certDER)
+	c.Assert(err, jc.ErrorIsNil)
+	return signed, key
+}
+
+func (s *CASuite) TestMaxPathLenZeroRejectsSubCA(c *gc.C) {
+	zero := 0
+	rootCertPEM, rootKeyPEM, err := cert.NewCAWithOptions(
+		"root", "some-uuid", time.Now().Add(time.Hour), cert.CAOptions{MaxPathLen: &zero})
+	c.Assert(err, jc.ErrorIsNil)
+
+	rootCert, rootKey, err := (cert.Bundle{CertPEM: rootCertPEM, KeyPEM: rootKeyPEM}).Parse()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The root's MaxPathLen of 0 forbids any intermediate CA, so signing
+	// one succeeds (CreateCertificate doesn't itself enforce the
+	// constraint) but verifying a chain through it must fail.
+	intermediateCert, intermediateKey := signCA(c, "intermediate", rootCert, rootKey)
+
+	leafKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	c.Assert(err, jc.ErrorIsNil)
+	now := time.Now()
+	leafTemplate := &x509.Certificate{
+		Subject:      pkix.Name{CommonName: "leaf"},
+		SerialNumber: big.NewInt(1),
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, intermediateCert, &leafKey.PublicKey, intermediateKey)
+	c.Assert(err, jc.ErrorIsNil)
+	leafCert, err := x509.ParseCertificate(leafDER)
+	c.Assert(err, jc.ErrorIsNil)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(rootCert)
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(intermediateCert)
+
+	_, err = leafCert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	c.Assert(err, gc.FitsTypeOf, x509.CertificateInvalidError{})
+	c.Assert(err.(x509.CertificateInvalidError).Reason, gc.Equals, x509.TooManyIntermediates)
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cert
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/juju/errors"
+)
+
+// ParseCertificateChain parses every CERTIFICATE block in pemData and
+// returns them in the order they appear, so that callers verifying a
+// leaf against an intermediate don't lose anything after the first
+// certificate the way x509.ParseCertificate would. Other PEM block types,
+// such as a private key sharing the same file, are skipped. It returns an
+// error only if pemData contains no certificate at all.
+func ParseCertificateChain(pemData []byte) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	rest := pemData
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Annotatef(err, "parsing certificate %d", len(chain))
+		}
+		chain = append(chain, certificate)
+	}
+	if len(chain) == 0 {
+		return nil, errors.Errorf("no certificates found")
+	}
+	return chain, nil
+}
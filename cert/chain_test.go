@@ -0,0 +1,63 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cert_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	utilscert "github.com/juju/utils/v3/cert"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/testing"
+)
+
+type ChainSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&ChainSuite{})
+
+func (s *ChainSuite) TestParseCertificateChainSingleCert(c *gc.C) {
+	caCertPEM, _, err := utilscert.NewCA("testing", "some-uuid", time.Now().Add(time.Hour), 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	chain, err := cert.ParseCertificateChain([]byte(caCertPEM))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(chain, gc.HasLen, 1)
+	c.Assert(chain[0].Subject.CommonName, gc.Equals, "testing")
+}
+
+func (s *ChainSuite) TestParseCertificateChainBundle(c *gc.C) {
+	caCertPEM, caKeyPEM, err := utilscert.NewCA("testing", "some-uuid", time.Now().Add(time.Hour), 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	bundle, err := cert.NewServer(caCertPEM, caKeyPEM, "server", "some-uuid", time.Now().Add(time.Minute), []string{"localhost"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	chain, err := cert.ParseCertificateChain([]byte(bundle.CertPEM + caCertPEM))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(chain, gc.HasLen, 2)
+	c.Assert(chain[0].Subject.CommonName, gc.Equals, "server")
+	c.Assert(chain[1].Subject.CommonName, gc.Equals, "testing")
+}
+
+func (s *ChainSuite) TestParseCertificateChainSkipsNonCertificateBlocks(c *gc.C) {
+	caCertPEM, caKeyPEM, err := utilscert.NewCA("testing", "some-uuid", time.Now().Add(time.Hour), 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	chain, err := cert.ParseCertificateChain([]byte(caCertPEM + caKeyPEM))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(chain, gc.HasLen, 1)
+	c.Assert(chain[0].Subject.CommonName, gc.Equals, "testing")
+}
+
+func (s *ChainSuite) TestParseCertificateChainNoCertificates(c *gc.C) {
+	_, caKeyPEM, err := utilscert.NewCA("testing", "some-uuid", time.Now().Add(time.Hour), 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = cert.ParseCertificateChain([]byte(caKeyPEM))
+	c.Assert(err, gc.ErrorMatches, "no certificates found")
+}
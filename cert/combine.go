@@ -0,0 +1,50 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cert
+
+import (
+	"encoding/pem"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// CombinePEM concatenates certPEM and keyPEM into a single PEM blob, for
+// tools that expect a certificate and its private key in one file rather
+// than as separate values.
+func CombinePEM(certPEM, keyPEM []byte) []byte {
+	combined := make([]byte, 0, len(certPEM)+len(keyPEM))
+	combined = append(combined, certPEM...)
+	combined = append(combined, keyPEM...)
+	return combined
+}
+
+// SplitPEM is the inverse of CombinePEM: it extracts the first CERTIFICATE
+// block and the first PRIVATE KEY block from combined, regardless of the
+// order they appear in, and returns each as its own PEM blob. It returns
+// an error if either block is missing.
+func SplitPEM(combined []byte) (certPEM, keyPEM []byte, err error) {
+	rest := combined
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		encoded := pem.EncodeToMemory(block)
+		switch {
+		case block.Type == "CERTIFICATE" && certPEM == nil:
+			certPEM = encoded
+		case strings.HasSuffix(block.Type, "PRIVATE KEY") && keyPEM == nil:
+			keyPEM = encoded
+		}
+	}
+	if certPEM == nil {
+		return nil, nil, errors.Errorf("no certificate found")
+	}
+	if keyPEM == nil {
+		return nil, nil, errors.Errorf("no private key found")
+	}
+	return certPEM, keyPEM, nil
+}
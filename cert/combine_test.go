@@ -0,0 +1,52 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cert_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	utilscert "github.com/juju/utils/v3/cert"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/testing"
+)
+
+type CombineSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&CombineSuite{})
+
+func (s *CombineSuite) TestCombineAndSplitRoundTrip(c *gc.C) {
+	caCertPEM, caKeyPEM, err := utilscert.NewCA("testing", "some-uuid", time.Now().Add(time.Hour), 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	bundle, err := cert.NewServer(caCertPEM, caKeyPEM, "server", "some-uuid", time.Now().Add(time.Minute), []string{"localhost"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	combined := cert.CombinePEM([]byte(bundle.CertPEM), []byte(bundle.KeyPEM))
+
+	gotCertPEM, gotKeyPEM, err := cert.SplitPEM(combined)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(gotCertPEM), jc.DeepEquals, bundle.CertPEM)
+	c.Assert(string(gotKeyPEM), jc.DeepEquals, bundle.KeyPEM)
+}
+
+func (s *CombineSuite) TestSplitPEMMissingKey(c *gc.C) {
+	caCertPEM, _, err := utilscert.NewCA("testing", "some-uuid", time.Now().Add(time.Hour), 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, _, err = cert.SplitPEM([]byte(caCertPEM))
+	c.Assert(err, gc.ErrorMatches, "no private key found")
+}
+
+func (s *CombineSuite) TestSplitPEMMissingCertificate(c *gc.C) {
+	_, caKeyPEM, err := utilscert.NewCA("testing", "some-uuid", time.Now().Add(time.Hour), 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, _, err = cert.SplitPEM([]byte(caKeyPEM))
+	c.Assert(err, gc.ErrorMatches, "no certificate found")
+}
@@ -0,0 +1,28 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cert
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// IsExpiringSoon reports whether certPEM's certificate expires within the
+// given window of now, so that an agent can decide to renew a certificate
+// before it actually stops working. A certificate that has already expired
+// counts as expiring soon regardless of within.
+func IsExpiringSoon(certPEM []byte, within time.Duration, now time.Time) (bool, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return false, errors.Errorf("no certificate found")
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false, errors.Annotate(err, "parsing certificate")
+	}
+	return !certificate.NotAfter.After(now.Add(within)), nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cert_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	utilscert "github.com/juju/utils/v3/cert"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/testing"
+)
+
+type ExpirySuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&ExpirySuite{})
+
+func newCertExpiring(c *gc.C, expiry time.Time) []byte {
+	certPEM, _, err := utilscert.NewCA("testing", "some-uuid", expiry, 0)
+	c.Assert(err, jc.ErrorIsNil)
+	return []byte(certPEM)
+}
+
+func (s *ExpirySuite) TestIsExpiringSoonJustInsideWindow(c *gc.C) {
+	now := time.Now()
+	certPEM := newCertExpiring(c, now.Add(time.Hour))
+
+	soon, err := cert.IsExpiringSoon(certPEM, 2*time.Hour, now)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(soon, jc.IsTrue)
+}
+
+func (s *ExpirySuite) TestIsExpiringSoonJustOutsideWindow(c *gc.C) {
+	now := time.Now()
+	certPEM := newCertExpiring(c, now.Add(2*time.Hour))
+
+	soon, err := cert.IsExpiringSoon(certPEM, time.Hour, now)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(soon, jc.IsFalse)
+}
+
+func (s *ExpirySuite) TestIsExpiringSoonAlreadyExpired(c *gc.C) {
+	now := time.Now()
+	certPEM := newCertExpiring(c, now.Add(-time.Hour))
+
+	soon, err := cert.IsExpiringSoon(certPEM, time.Minute, now)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(soon, jc.IsTrue)
+}
+
+func (s *ExpirySuite) TestIsExpiringSoonInvalidPEM(c *gc.C) {
+	_, err := cert.IsExpiringSoon([]byte("not a certificate"), time.Hour, time.Now())
+	c.Assert(err, gc.ErrorMatches, "no certificate found")
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cert
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// NewPool parses each of caCertPEMs and adds the resulting certificates to
+// a new x509.CertPool, so that callers that need to trust several CAs at
+// once (for example an agent that talks to more than one controller)
+// don't each have to build the pool by hand. It returns an error if any
+// blob contains no certificate.
+func NewPool(caCertPEMs ...[]byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for i, caCertPEM := range caCertPEMs {
+		rest := caCertPEM
+		found := false
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, errors.Annotatef(err, "parsing CA certificate %d", i)
+			}
+			pool.AddCert(certificate)
+			found = true
+		}
+		if !found {
+			return nil, errors.Errorf("no certificate found in CA certificate %d", i)
+		}
+	}
+	return pool, nil
+}
+
+// VerifyAgainstPool parses srvCertPEM and verifies it chains to one of the
+// CAs in pool as of now.
+func VerifyAgainstPool(srvCertPEM []byte, pool *x509.CertPool, now time.Time) error {
+	block, _ := pem.Decode(srvCertPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return errors.Errorf("no certificate found in server certificate")
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return errors.Annotate(err, "parsing server certificate")
+	}
+	_, err = certificate.Verify(x509.VerifyOptions{
+		Roots:       pool,
+		CurrentTime: now,
+	})
+	return errors.Trace(err)
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cert_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	utilscert "github.com/juju/utils/v3/cert"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/testing"
+)
+
+type PoolSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&PoolSuite{})
+
+func (s *PoolSuite) TestVerifyAgainstPool(c *gc.C) {
+	now := time.Now()
+	caExpiry := now.Add(time.Hour)
+
+	caCertPEM, caKeyPEM, err := utilscert.NewCA("testing", "some-uuid", caExpiry, 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	bundle, err := cert.NewServer(caCertPEM, caKeyPEM, "server", "some-uuid", now.Add(time.Minute), []string{"localhost"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Some other, unrelated CA that should be harmless to have in the pool.
+	otherCACertPEM, _, err := utilscert.NewCA("other", "other-uuid", caExpiry, 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	pool, err := cert.NewPool([]byte(otherCACertPEM), []byte(caCertPEM))
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = cert.VerifyAgainstPool([]byte(bundle.CertPEM), pool, now)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *PoolSuite) TestVerifyAgainstPoolMissingCA(c *gc.C) {
+	now := time.Now()
+	caExpiry := now.Add(time.Hour)
+
+	caCertPEM, caKeyPEM, err := utilscert.NewCA("testing", "some-uuid", caExpiry, 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	bundle, err := cert.NewServer(caCertPEM, caKeyPEM, "server", "some-uuid", now.Add(time.Minute), []string{"localhost"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	otherCACertPEM, _, err := utilscert.NewCA("other", "other-uuid", caExpiry, 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	pool, err := cert.NewPool([]byte(otherCACertPEM))
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = cert.VerifyAgainstPool([]byte(bundle.CertPEM), pool, now)
+	c.Assert(err, gc.NotNil)
+}
+
+func (s *PoolSuite) TestNewPoolNoCertificate(c *gc.C) {
+	_, err := cert.NewPool([]byte("not a pem blob"))
+	c.Assert(err, gc.ErrorMatches, "no certificate found in CA certificate 0")
+}
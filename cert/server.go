@@ -0,0 +1,84 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cert
+
+import (
+	"crypto/x509"
+	"time"
+
+	"github.com/juju/errors"
+	utilscert "github.com/juju/utils/v3/cert"
+)
+
+// ServerOptions customizes the x509 extended key usages written into the
+// certificate NewServerWithOptions signs, beyond NewServer's default of
+// ServerAuth only.
+type ServerOptions struct {
+	// ExtraExtKeyUsage appends additional extended key usages to the
+	// certificate's default ServerAuth usage, for example
+	// x509.ExtKeyUsageClientAuth when the same certificate will also be
+	// used for client authentication, as with controller peer
+	// connections.
+	ExtraExtKeyUsage []x509.ExtKeyUsage
+}
+
+// NewServer builds a CA-signed server certificate and key bundle, for use
+// as a leaf (non-CA) server certificate. Unlike utilscert.NewLeaf, it
+// checks that expiry does not fall after the CA's own expiry: a server
+// certificate that outlives its CA can never be verified once the CA
+// itself has expired.
+func NewServer(caCertPEM, caKeyPEM string, commonName, uuid string, expiry time.Time, hostnames []string) (Bundle, error) {
+	return NewServerWithOptions(caCertPEM, caKeyPEM, commonName, uuid, expiry, hostnames, ServerOptions{})
+}
+
+// NewServerWithOptions is NewServer with additional extended key usages,
+// for deployments that want a single certificate usable for both server
+// and client authentication.
+func NewServerWithOptions(
+	caCertPEM, caKeyPEM string, commonName, uuid string, expiry time.Time, hostnames []string, opts ServerOptions,
+) (Bundle, error) {
+	extKeyUsage := append([]x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}, opts.ExtraExtKeyUsage...)
+	return newLeaf(caCertPEM, caKeyPEM, &utilscert.Config{
+		CommonName:  commonName,
+		UUID:        uuid,
+		Expiry:      expiry,
+		Hostnames:   hostnames,
+		ExtKeyUsage: extKeyUsage,
+	}, "server certificate expiry exceeds CA expiry")
+}
+
+// NewClient builds a CA-signed client certificate and key bundle, for use
+// in client certificate authentication. Like NewServer, it checks that
+// expiry does not fall after the CA's own expiry.
+func NewClient(caCertPEM, caKeyPEM string, commonName, uuid string, expiry time.Time) (Bundle, error) {
+	return newLeaf(caCertPEM, caKeyPEM, &utilscert.Config{
+		CommonName: commonName,
+		UUID:       uuid,
+		Expiry:     expiry,
+		Client:     true,
+	}, "client certificate expiry exceeds CA expiry")
+}
+
+// newLeaf validates cfg.Expiry against the CA's own expiry and, if it
+// passes, signs cfg into a leaf certificate and key bundle.
+func newLeaf(caCertPEM, caKeyPEM string, cfg *utilscert.Config, expiryErr string) (Bundle, error) {
+	caCert, err := utilscert.ParseCert(caCertPEM)
+	if err != nil {
+		return Bundle{}, errors.Annotate(err, "parsing CA certificate")
+	}
+	if !caCert.IsCA || caCert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return Bundle{}, errors.New("CA certificate is not a valid CA")
+	}
+	if cfg.Expiry.After(caCert.NotAfter) {
+		return Bundle{}, errors.New(expiryErr)
+	}
+
+	cfg.CA = []byte(caCertPEM)
+	cfg.CAKey = []byte(caKeyPEM)
+	certPEM, keyPEM, err := utilscert.NewLeaf(cfg)
+	if err != nil {
+		return Bundle{}, errors.Trace(err)
+	}
+	return Bundle{CertPEM: certPEM, KeyPEM: keyPEM}, nil
+}
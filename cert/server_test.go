@@ -0,0 +1,139 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cert_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	utilscert "github.com/juju/utils/v3/cert"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/testing"
+)
+
+type ServerSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&ServerSuite{})
+
+func (s *ServerSuite) newCA(c *gc.C, expiry time.Time) (string, string) {
+	caCertPEM, caKeyPEM, err := utilscert.NewCA("testing", "some-uuid", expiry, 0)
+	c.Assert(err, jc.ErrorIsNil)
+	return caCertPEM, caKeyPEM
+}
+
+// newCAWithoutCertSign builds a self-signed certificate that claims to be
+// a CA (IsCA set, BasicConstraintsValid set) but whose key usage omits
+// KeyUsageCertSign, as a malformed or deliberately constrained CA might.
+func (s *ServerSuite) newCAWithoutCertSign(c *gc.C, expiry time.Time) (string, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	c.Assert(err, jc.ErrorIsNil)
+
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "testing", Organization: []string{"juju"}},
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              expiry,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	c.Assert(err, jc.ErrorIsNil)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return string(certPEM), string(keyPEM)
+}
+
+func (s *ServerSuite) TestNewServerRejectsCAWithoutCertSignUsage(c *gc.C) {
+	caCertPEM, caKeyPEM := s.newCAWithoutCertSign(c, time.Now().Add(time.Hour))
+
+	_, err := cert.NewServer(caCertPEM, caKeyPEM, "server", "some-uuid", time.Now().Add(time.Minute), []string{"localhost"})
+	c.Assert(err, gc.ErrorMatches, "CA certificate is not a valid CA")
+}
+
+func (s *ServerSuite) TestNewServer(c *gc.C) {
+	caExpiry := time.Now().Add(time.Hour)
+	caCertPEM, caKeyPEM := s.newCA(c, caExpiry)
+
+	bundle, err := cert.NewServer(caCertPEM, caKeyPEM, "server", "some-uuid", time.Now().Add(time.Minute), []string{"localhost"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	parsedCert, _, err := bundle.Parse()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsedCert.Subject.CommonName, gc.Equals, "server")
+}
+
+func (s *ServerSuite) TestNewServerExpiryExceedsCAExpiry(c *gc.C) {
+	caExpiry := time.Now().Add(time.Hour)
+	caCertPEM, caKeyPEM := s.newCA(c, caExpiry)
+
+	_, err := cert.NewServer(caCertPEM, caKeyPEM, "server", "some-uuid", caExpiry.Add(time.Minute), []string{"localhost"})
+	c.Assert(err, gc.ErrorMatches, "server certificate expiry exceeds CA expiry")
+}
+
+func (s *ServerSuite) TestNewServerExpiryAtCAExpiryBoundary(c *gc.C) {
+	caCertPEM, caKeyPEM := s.newCA(c, time.Now().Add(time.Hour))
+	caCert, err := utilscert.ParseCert(caCertPEM)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// A server certificate expiring at exactly the same instant as the
+	// CA (x509 NotAfter has only second precision, which is what the
+	// boundary check compares against) is still verifiable throughout
+	// its lifetime, so this should be allowed rather than rejected.
+	bundle, err := cert.NewServer(caCertPEM, caKeyPEM, "server", "some-uuid", caCert.NotAfter, []string{"localhost"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	parsedCert, _, err := bundle.Parse()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsedCert.NotAfter.Equal(caCert.NotAfter) || parsedCert.NotAfter.Before(caCert.NotAfter), jc.IsTrue)
+}
+
+func (s *ServerSuite) TestNewServerWithOptionsDualPurpose(c *gc.C) {
+	caExpiry := time.Now().Add(time.Hour)
+	caCertPEM, caKeyPEM := s.newCA(c, caExpiry)
+
+	bundle, err := cert.NewServerWithOptions(
+		caCertPEM, caKeyPEM, "server", "some-uuid", time.Now().Add(time.Minute), []string{"localhost"},
+		cert.ServerOptions{ExtraExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	parsedCert, _, err := bundle.Parse()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsedCert.ExtKeyUsage, jc.SameContents, []x509.ExtKeyUsage{
+		x509.ExtKeyUsageServerAuth,
+		x509.ExtKeyUsageClientAuth,
+	})
+}
+
+func (s *ServerSuite) TestNewServerDefaultUsageIsServerAuthOnly(c *gc.C) {
+	caExpiry := time.Now().Add(time.Hour)
+	caCertPEM, caKeyPEM := s.newCA(c, caExpiry)
+
+	bundle, err := cert.NewServer(caCertPEM, caKeyPEM, "server", "some-uuid", time.Now().Add(time.Minute), []string{"localhost"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	parsedCert, _, err := bundle.Parse()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsedCert.ExtKeyUsage, jc.DeepEquals, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth})
+}
+
+func (s *ServerSuite) TestNewClientExpiryExceedsCAExpiry(c *gc.C) {
+	caExpiry := time.Now().Add(time.Hour)
+	caCertPEM, caKeyPEM := s.newCA(c, caExpiry)
+
+	_, err := cert.NewClient(caCertPEM, caKeyPEM, "client", "some-uuid", caExpiry.Add(time.Minute))
+	c.Assert(err, gc.ErrorMatches, "client certificate expiry exceeds CA expiry")
+}
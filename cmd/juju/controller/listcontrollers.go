@@ -14,7 +14,6 @@ import (
 	"github.com/juju/names/v4"
 
 	"github.com/juju/juju/api/base"
-	"github.com/juju/juju/api/controller/controller"
 	jujucmd "github.com/juju/juju/cmd"
 	"github.com/juju/juju/cmd/modelcmd"
 	"github.com/juju/juju/core/status"
@@ -79,7 +78,7 @@ func (c *listControllersCommand) getAPI(controllerName string) (ControllerAccess
 	if err != nil {
 		return nil, errors.Annotate(err, "opening API connection")
 	}
-	return controller.NewClient(api), nil
+	return newControllerAccessAPIClient(api), nil
 }
 
 // Run implements Command.Run
@@ -5,21 +5,29 @@ package controller
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/juju/cmd/v3"
 	"github.com/juju/errors"
 	"github.com/juju/gnuflag"
 	"github.com/juju/names/v4"
+	"github.com/juju/version/v2"
 
 	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/client/usermanager"
 	"github.com/juju/juju/api/controller/controller"
 	jujucmd "github.com/juju/juju/cmd"
+	jujucommon "github.com/juju/juju/cmd/juju/common"
 	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/core/life"
 	"github.com/juju/juju/core/model"
 	"github.com/juju/juju/core/permission"
 	"github.com/juju/juju/core/status"
 	"github.com/juju/juju/environs/bootstrap"
+	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/jujuclient"
 	"github.com/juju/juju/pki"
 	"github.com/juju/juju/rpc/params"
@@ -32,11 +40,16 @@ var usageShowControllerDetails = `
 Shows extended information about a controller(s) as well as related models
 and user login details.
 
+Use --list-model-uuids to print a flat list of "controller:owner/model:uuid"
+triples for the named controllers instead, which is easier for tooling to
+consume than the nested models block in the default output.
+
 Examples:
     juju show-controller
     juju show-controller aws google
-    
-See also: 
+    juju show-controller aws google --list-model-uuids
+
+See also:
     controllers`[1:]
 
 type showControllerCommand struct {
@@ -47,8 +60,13 @@ type showControllerCommand struct {
 	mu    sync.Mutex
 	api   func(controllerName string) ControllerAccessAPI
 
-	controllerNames []string
-	showPasswords   bool
+	controllerNames   []string
+	showPasswords     bool
+	modelsOnly        bool
+	caFingerprintOnly bool
+	noRefresh         bool
+	listModelUUIDs    bool
+	includeDisabled   bool
 }
 
 // NewShowControllerCommand returns a command to show details of the desired controllers.
@@ -61,6 +79,15 @@ func NewShowControllerCommand() cmd.Command {
 
 // Init implements Command.Init.
 func (c *showControllerCommand) Init(args []string) (err error) {
+	if c.modelsOnly && c.showPasswords {
+		return errors.Errorf("--models-only and --show-password are mutually exclusive")
+	}
+	if c.caFingerprintOnly && (c.modelsOnly || c.showPasswords) {
+		return errors.Errorf("--ca-fingerprint-only cannot be used with --models-only or --show-password")
+	}
+	if c.listModelUUIDs && (c.modelsOnly || c.showPasswords || c.caFingerprintOnly || c.noRefresh) {
+		return errors.Errorf("--list-model-uuids cannot be used with --models-only, --show-password, --ca-fingerprint-only or --no-refresh")
+	}
 	c.controllerNames = args
 	return nil
 }
@@ -79,9 +106,15 @@ func (c *showControllerCommand) Info() *cmd.Info {
 func (c *showControllerCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.CommandBase.SetFlags(f)
 	f.BoolVar(&c.showPasswords, "show-password", false, "Show password for logged in user")
+	f.BoolVar(&c.modelsOnly, "models-only", false, "Only show model details, omitting controller details and account information")
+	f.BoolVar(&c.caFingerprintOnly, "ca-fingerprint-only", false, "Only show the SHA-256 fingerprint of the ca-cert in the client store, without contacting the controller")
+	f.BoolVar(&c.noRefresh, "no-refresh", false, "Print from the local client store only, without contacting the controller to refresh model and machine counts")
+	f.BoolVar(&c.listModelUUIDs, "list-model-uuids", false, "List all models for the named controllers as a flat list of controller:owner/model:uuid triples")
+	f.BoolVar(&c.includeDisabled, "include-disabled", true, "Include models that are dying or dead in the listing; they are always counted in the totals regardless")
 	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
-		"yaml": cmd.FormatYaml,
-		"json": cmd.FormatJson,
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatShowControllerTabular,
 	})
 }
 
@@ -99,6 +132,7 @@ type ControllerAccessAPI interface {
 	MongoVersion() (string, error)
 	IdentityProviderURL() (string, error)
 	ControllerVersion() (controller.ControllerVersion, error)
+	AccountLastConnection(user string) (*time.Time, error)
 	Close() error
 }
 
@@ -110,7 +144,37 @@ func (c *showControllerCommand) getAPI(controllerName string) (ControllerAccessA
 	if err != nil {
 		return nil, errors.Annotate(err, "opening API connection")
 	}
-	return controller.NewClient(api), nil
+	return newControllerAccessAPIClient(api), nil
+}
+
+// newControllerAccessAPIClient builds the ControllerAccessAPI used by the
+// controller commands in this package from a freshly opened API connection.
+func newControllerAccessAPIClient(api base.APICallCloser) ControllerAccessAPI {
+	return &controllerAccessAPIClient{
+		Client:      controller.NewClient(api),
+		userManager: usermanager.NewClient(api),
+	}
+}
+
+// controllerAccessAPIClient combines the controller and user manager
+// facades so show-controller can report per-account details, such as
+// last-connection time, that the controller facade alone doesn't expose.
+type controllerAccessAPIClient struct {
+	*controller.Client
+	userManager *usermanager.Client
+}
+
+// AccountLastConnection returns the time the given user last connected to
+// the controller, or nil if the controller doesn't record it.
+func (c *controllerAccessAPIClient) AccountLastConnection(user string) (*time.Time, error) {
+	infos, err := c.userManager.UserInfo([]string{user}, usermanager.ActiveUsers)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(infos) == 0 {
+		return nil, errors.NotFoundf("user %q", user)
+	}
+	return infos[0].LastConnection, nil
 }
 
 // Run implements Command.Run
@@ -118,13 +182,25 @@ func (c *showControllerCommand) Run(ctx *cmd.Context) error {
 	controllerNames := c.controllerNames
 	if len(controllerNames) == 0 {
 		currentController, err := modelcmd.DetermineCurrentController(c.store)
-		if errors.IsNotFound(err) {
-			return errors.New("there is no active controller")
-		} else if err != nil {
+		if err != nil {
+			// Only report the generic "no active controller" message when
+			// nothing was explicitly requested; if JUJU_CONTROLLER or
+			// JUJU_MODEL named a controller that doesn't exist, surface
+			// that error instead so the user knows what went wrong.
+			noOverride := os.Getenv(osenv.JujuControllerEnvKey) == "" && os.Getenv(osenv.JujuModelEnvKey) == ""
+			if errors.IsNotFound(err) && noOverride {
+				return errors.New("there is no active controller")
+			}
 			return errors.Trace(err)
 		}
 		controllerNames = []string{currentController}
 	}
+	if c.caFingerprintOnly {
+		return c.showCAFingerprints(ctx, controllerNames)
+	}
+	if c.listModelUUIDs {
+		return c.listAllModelUUIDs(ctx, controllerNames)
+	}
 	controllers := make(map[string]ShowControllerDetails)
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -133,6 +209,14 @@ func (c *showControllerCommand) Run(ctx *cmd.Context) error {
 		if err != nil {
 			return err
 		}
+		if c.noRefresh {
+			details, err := c.convertControllerForShowFromStore(controllerName, one)
+			if err != nil {
+				return err
+			}
+			controllers[controllerName] = details
+			continue
+		}
 		var access string
 		client, err := c.getAPI(controllerName)
 		if err != nil {
@@ -148,6 +232,7 @@ func (c *showControllerCommand) Run(ctx *cmd.Context) error {
 			agentGitCommit    string
 		)
 
+		var lastConnection *time.Time
 		accountDetails, err := c.store.AccountDetails(controllerName)
 		if err != nil {
 			fmt.Fprintln(ctx.Stderr, err)
@@ -155,6 +240,10 @@ func (c *showControllerCommand) Run(ctx *cmd.Context) error {
 		} else {
 			access = c.userAccess(client, ctx, accountDetails.User)
 			controllerVersion = c.controllerModelVersion(client, ctx)
+			lastConnection, err = client.AccountLastConnection(accountDetails.User)
+			if err != nil && !errors.IsNotSupported(err) {
+				details.Errors = append(details.Errors, err.Error())
+			}
 		}
 
 		ver, err := client.ControllerVersion()
@@ -238,12 +327,69 @@ func (c *showControllerCommand) Run(ctx *cmd.Context) error {
 		}
 
 		c.convertControllerForShow(&details, controllerName, one, access, allModels,
-			modelStatusResults, mongoVersion, controllerVersion, agentGitCommit, identityURL)
+			modelStatusResults, mongoVersion, controllerVersion, agentGitCommit, identityURL, lastConnection)
 		controllers[controllerName] = details
 	}
+	if c.modelsOnly {
+		modelsOnly := make(map[string]ModelsOnlyDetails, len(controllers))
+		for name, details := range controllers {
+			modelsOnly[name] = ModelsOnlyDetails{
+				Models:       details.Models,
+				Totals:       details.Totals,
+				CurrentModel: details.CurrentModel,
+			}
+		}
+		return c.out.Write(ctx, modelsOnly)
+	}
 	return c.out.Write(ctx, controllers)
 }
 
+// showCAFingerprints prints the SHA-256 fingerprint of the ca-cert already
+// recorded in the client store for each named controller, one per line,
+// without opening an API connection to any of them.
+func (c *showControllerCommand) showCAFingerprints(ctx *cmd.Context, controllerNames []string) error {
+	for _, controllerName := range controllerNames {
+		one, err := c.store.ControllerByName(controllerName)
+		if err != nil {
+			return err
+		}
+		if one.CACert == "" {
+			return errors.Errorf("controller %q has no ca-cert in the client store", controllerName)
+		}
+		fingerprint, _, err := pki.Fingerprint([]byte(one.CACert))
+		if err != nil {
+			return errors.Annotatef(err, "computing ca-cert fingerprint for controller %q", controllerName)
+		}
+		fmt.Fprintf(ctx.Stdout, "%s: %s\n", controllerName, fingerprint)
+	}
+	return nil
+}
+
+// listAllModelUUIDs writes a flat list of "controller:owner/model:uuid"
+// triples for every model on each named controller, so tooling that wants
+// model UUIDs across controllers doesn't have to reparse the models block
+// nested under each controller in the default output.
+func (c *showControllerCommand) listAllModelUUIDs(ctx *cmd.Context, controllerNames []string) error {
+	var triples []string
+	for _, controllerName := range controllerNames {
+		client, err := c.getAPI(controllerName)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		allModels, err := client.AllModels()
+		if err != nil {
+			return errors.Annotatef(err, "listing models for controller %q", controllerName)
+		}
+		for _, m := range allModels {
+			qualifiedName := jujuclient.JoinOwnerModelName(names.NewUserTag(m.Owner), m.Name)
+			triples = append(triples, fmt.Sprintf("%s:%s:%s", controllerName, qualifiedName, m.UUID))
+		}
+	}
+	return c.out.Write(ctx, triples)
+}
+
 func (c *showControllerCommand) userAccess(client ControllerAccessAPI, ctx *cmd.Context, user string) string {
 	var access string
 	userAccess, err := client.GetControllerAccess(user)
@@ -287,9 +433,19 @@ type ShowControllerDetails struct {
 	// Nodes is a collection of all k8s pods forming the controller cluster.
 	Nodes map[string]MachineDetails `yaml:"controller-nodes,omitempty" json:"controller-nodes,omitempty"`
 
+	// HASummary reports how many controller members currently hold a
+	// database vote out of how many want one, and whether that's enough
+	// to retain quorum. It is only populated when there's more than one
+	// controller member, mirroring when per-machine HAStatus is shown.
+	HASummary string `yaml:"ha-summary,omitempty" json:"ha-summary,omitempty"`
+
 	// Models is a collection of all models for this controller.
 	Models map[string]ModelDetails `yaml:"models,omitempty" json:"models,omitempty"`
 
+	// Totals holds the machine and core count summed across all models,
+	// populated only when the controller hosts more than one model.
+	Totals *ModelTotals `yaml:"totals,omitempty" json:"totals,omitempty"`
+
 	// CurrentModel is the name of the current model for this controller
 	CurrentModel string `yaml:"current-model,omitempty" json:"current-model,omitempty"`
 
@@ -300,6 +456,30 @@ type ShowControllerDetails struct {
 	Errors []string `yaml:"errors,omitempty" json:"errors,omitempty"`
 }
 
+// ModelTotals holds machine and core count rollups across all of a
+// controller's models, for capacity planning at a glance.
+type ModelTotals struct {
+	// MachineCount holds the total number of machines across all models.
+	MachineCount int `yaml:"machine-count" json:"machine-count"`
+
+	// CoreCount holds the total number of cores across all models.
+	CoreCount int `yaml:"core-count" json:"core-count"`
+}
+
+// ModelsOnlyDetails holds the model-related subset of ShowControllerDetails
+// printed when --models-only is used.
+type ModelsOnlyDetails struct {
+	// Models is a collection of all models for this controller.
+	Models map[string]ModelDetails `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// Totals holds the machine and core count summed across all models,
+	// populated only when the controller hosts more than one model.
+	Totals *ModelTotals `yaml:"totals,omitempty" json:"totals,omitempty"`
+
+	// CurrentModel is the name of the current model for this controller
+	CurrentModel string `yaml:"current-model,omitempty" json:"current-model,omitempty"`
+}
+
 // ControllerDetails holds details of a controller to show.
 type ControllerDetails struct {
 	// ControllerUUID is the unique ID for the controller.
@@ -330,6 +510,12 @@ type ControllerDetails struct {
 	// controller.
 	MongoVersion string `yaml:"mongo-version,omitempty" json:"mongo-version,omitempty"`
 
+	// MongoUpgradeRecommended is set when MongoVersion is below
+	// minRecommendedMongoVersion. It is surfaced as a separate field in
+	// json output; yaml output instead annotates MongoVersion directly,
+	// see MarshalYAML.
+	MongoUpgradeRecommended bool `yaml:"-" json:"mongo-upgrade-recommended,omitempty"`
+
 	// IdentityURL contails the address of an external identity provider
 	// if one has been configured for this controller.
 	IdentityURL string `yaml:"identity-url,omitempty" json:"identity-url,omitempty"`
@@ -341,6 +527,39 @@ type ControllerDetails struct {
 	CACert string `yaml:"ca-cert" json:"ca-cert"`
 }
 
+// minRecommendedMongoVersion is the oldest mongo server version this
+// command considers current. Controllers reporting an older version have
+// an upgrade flagged in show-controller output.
+var minRecommendedMongoVersion = version.MustParse("3.4.0")
+
+// mongoVersionOutdated reports whether reported is older than
+// minRecommendedMongoVersion. An unparsable or empty version (for example
+// when show-controller is run with --no-refresh, which never contacts the
+// controller) is treated as not outdated, since there is nothing useful to
+// tell the user.
+func mongoVersionOutdated(reported string) bool {
+	current, err := version.Parse(reported)
+	if err != nil {
+		return false
+	}
+	return current.Compare(minRecommendedMongoVersion) < 0
+}
+
+// controllerDetailsNoMarshal is used to avoid infinite recursion when
+// ControllerDetails.MarshalYAML calls back into the yaml package.
+type controllerDetailsNoMarshal ControllerDetails
+
+// MarshalYAML implements yaml.Marshaler. Unlike json output, which reports
+// MongoUpgradeRecommended as its own field, yaml output keeps a single
+// mongo-version line and annotates it in place.
+func (d ControllerDetails) MarshalYAML() (interface{}, error) {
+	out := controllerDetailsNoMarshal(d)
+	if out.MongoUpgradeRecommended {
+		out.MongoVersion += " (upgrade recommended)"
+	}
+	return out, nil
+}
+
 // ModelDetails holds details of a model to show.
 type MachineDetails struct {
 	// ID holds the id of the machine.
@@ -354,6 +573,10 @@ type MachineDetails struct {
 
 	// HAPrimary is set to true for a primary controller machine in HA.
 	HAPrimary bool `yaml:"ha-primary,omitempty" json:"ha-primary,omitempty"`
+
+	// CoreCount holds the number of cores on the machine. It is omitted
+	// when the controller doesn't report per-machine hardware info.
+	CoreCount *int `yaml:"core-count,omitempty" json:"core-count,omitempty"`
 }
 
 // ModelDetails holds details of a model to show.
@@ -361,6 +584,18 @@ type ModelDetails struct {
 	// ModelUUID holds the details of a model.
 	ModelUUID string `yaml:"model-uuid" json:"uuid"`
 
+	// ModelType holds the model's type (iaas or caas), taken from
+	// base.UserModel.Type. Controllers too old to report a model type
+	// leave base.UserModel.Type empty, which AllModels treats as iaas;
+	// the omitempty tag exists for that unlikely empty case rather than
+	// for any model actually seen in practice.
+	ModelType model.ModelType `yaml:"type,omitempty" json:"type,omitempty"`
+
+	// Life holds the model's life, taken from base.ModelStatus. It is
+	// omitted for models --no-refresh can't reach the controller for,
+	// since the client store doesn't cache it.
+	Life life.Value `yaml:"life,omitempty" json:"life,omitempty"`
+
 	// MachineCount holds the number of machines in the model.
 	MachineCount *int `yaml:"machine-count,omitempty" json:"machine-count,omitempty"`
 
@@ -381,6 +616,69 @@ type AccountDetails struct {
 
 	// Password is the password for the account.
 	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+
+	// LastConnection is when the account last connected to this controller,
+	// omitted if the controller doesn't track it. It is rendered as an
+	// RFC3339 timestamp for json output and a human-friendly relative time
+	// otherwise.
+	LastConnection string `yaml:"last-connection,omitempty" json:"last-connection,omitempty"`
+}
+
+// convertControllerForShowFromStore builds a ShowControllerDetails entirely
+// from data already cached in the client store, for --no-refresh. Per-model
+// machine/core counts and the controller-machines breakdown are never
+// cached, so they're simply omitted rather than guessed at.
+func (c *showControllerCommand) convertControllerForShowFromStore(
+	controllerName string,
+	details *jujuclient.ControllerDetails,
+) (ShowControllerDetails, error) {
+	caFingerprint, _, _ := pki.Fingerprint([]byte(details.CACert))
+
+	result := ShowControllerDetails{
+		Details: ControllerDetails{
+			ControllerUUID: details.ControllerUUID,
+			APIEndpoints:   details.APIEndpoints,
+			CACert:         details.CACert,
+			CAFingerprint:  caFingerprint,
+			Cloud:          details.Cloud,
+			CloudRegion:    details.CloudRegion,
+			AgentVersion:   details.AgentVersion,
+		},
+	}
+
+	models, err := c.store.AllModels(controllerName)
+	if err != nil && !errors.IsNotFound(err) {
+		return result, errors.Trace(err)
+	}
+	if len(models) > 0 {
+		result.Models = make(map[string]ModelDetails, len(models))
+		for name, m := range models {
+			result.Models[name] = ModelDetails{ModelUUID: m.ModelUUID, ModelType: m.ModelType}
+		}
+	}
+
+	currentModel, err := c.store.CurrentModel(controllerName)
+	if err != nil && !errors.IsNotFound(err) {
+		return result, errors.Trace(err)
+	}
+	result.CurrentModel = currentModel
+
+	accountDetails, err := c.store.AccountDetails(controllerName)
+	if err != nil && !errors.IsNotFound(err) {
+		return result, errors.Trace(err)
+	}
+	if accountDetails != nil {
+		account := &AccountDetails{
+			User:   accountDetails.User,
+			Access: accountDetails.LastKnownAccess,
+		}
+		if c.showPasswords {
+			account.Password = accountDetails.Password
+		}
+		result.Account = account
+	}
+
+	return result, nil
 }
 
 func (c *showControllerCommand) convertControllerForShow(
@@ -394,25 +692,27 @@ func (c *showControllerCommand) convertControllerForShow(
 	controllerVersion string,
 	agentGitCommit string,
 	identityURL string,
+	lastConnection *time.Time,
 ) {
 	// CA cert will always be valid so no need to check for errors here
 	caFingerprint, _, _ := pki.Fingerprint([]byte(details.CACert))
 
 	controller.Details = ControllerDetails{
-		ControllerUUID:         details.ControllerUUID,
-		APIEndpoints:           details.APIEndpoints,
-		CACert:                 details.CACert,
-		CAFingerprint:          caFingerprint,
-		Cloud:                  details.Cloud,
-		CloudRegion:            details.CloudRegion,
-		AgentVersion:           details.AgentVersion,
-		AgentGitCommit:         agentGitCommit,
-		ControllerModelVersion: controllerVersion,
-		MongoVersion:           mongoVersion,
-		IdentityURL:            identityURL,
+		ControllerUUID:          details.ControllerUUID,
+		APIEndpoints:            details.APIEndpoints,
+		CACert:                  details.CACert,
+		CAFingerprint:           caFingerprint,
+		Cloud:                   details.Cloud,
+		CloudRegion:             details.CloudRegion,
+		AgentVersion:            details.AgentVersion,
+		AgentGitCommit:          agentGitCommit,
+		ControllerModelVersion:  controllerVersion,
+		MongoVersion:            mongoVersion,
+		MongoUpgradeRecommended: mongoVersionOutdated(mongoVersion),
+		IdentityURL:             identityURL,
 	}
 	c.convertModelsForShow(controllerName, controller, allModels, modelStatusResults)
-	c.convertAccountsForShow(controllerName, controller, access)
+	c.convertAccountsForShow(controllerName, controller, access, lastConnection)
 	var controllerModelUUID string
 	for _, m := range allModels {
 		if m.Name == bootstrap.ControllerModelName {
@@ -441,7 +741,7 @@ func (c *showControllerCommand) convertControllerForShow(
 	}
 }
 
-func (c *showControllerCommand) convertAccountsForShow(controllerName string, controller *ShowControllerDetails, access string) {
+func (c *showControllerCommand) convertAccountsForShow(controllerName string, controller *ShowControllerDetails, access string, lastConnection *time.Time) {
 	storeDetails, err := c.store.AccountDetails(controllerName)
 	if err != nil && !errors.IsNotFound(err) {
 		controller.Errors = append(controller.Errors, err.Error())
@@ -456,6 +756,13 @@ func (c *showControllerCommand) convertAccountsForShow(controllerName string, co
 	if c.showPasswords {
 		details.Password = storeDetails.Password
 	}
+	if lastConnection != nil {
+		if c.out.Name() == "json" {
+			details.LastConnection = lastConnection.UTC().Format(time.RFC3339)
+		} else {
+			details.LastConnection = jujucommon.UserFriendlyDuration(*lastConnection, time.Now())
+		}
+	}
 	controller.Account = details
 }
 
@@ -469,8 +776,9 @@ func (c *showControllerCommand) convertModelsForShow(
 	if len(models) != len(modelStatus) {
 		controller.Errors = append(controller.Errors, "model status incomplete")
 	}
+	var totals ModelTotals
 	for i, m := range models {
-		modelDetails := ModelDetails{ModelUUID: m.UUID}
+		modelDetails := ModelDetails{ModelUUID: m.UUID, ModelType: m.Type}
 		if i >= len(modelStatus) {
 			continue
 		}
@@ -480,6 +788,7 @@ func (c *showControllerCommand) convertModelsForShow(
 				controller.Errors = append(controller.Errors, errors.Annotatef(result.Error, "model uuid %v", m.UUID).Error())
 			}
 		} else {
+			modelDetails.Life = result.Life
 			if m.Type == model.CAAS {
 				if result.UnitCount > 0 {
 					modelDetails.UnitCount = new(int)
@@ -489,15 +798,26 @@ func (c *showControllerCommand) convertModelsForShow(
 				if result.TotalMachineCount > 0 {
 					modelDetails.MachineCount = new(int)
 					*modelDetails.MachineCount = result.TotalMachineCount
+					totals.MachineCount += result.TotalMachineCount
 				}
 				if result.CoreCount > 0 {
 					modelDetails.CoreCount = new(int)
 					*modelDetails.CoreCount = result.CoreCount
+					totals.CoreCount += result.CoreCount
 				}
 			}
 		}
+		// A model's machine/core counts always contribute to the totals
+		// above, even when --include-disabled=false hides it from the
+		// per-model listing below.
+		if !c.includeDisabled && modelDetails.Life != "" && modelDetails.Life != life.Alive {
+			continue
+		}
 		controller.Models[m.Name] = modelDetails
 	}
+	if len(models) > 1 && (totals.MachineCount > 0 || totals.CoreCount > 0) {
+		controller.Totals = &totals
+	}
 	var err error
 	controller.CurrentModel, err = c.store.CurrentModel(controllerName)
 	if err != nil && !errors.IsNotFound(err) {
@@ -542,8 +862,53 @@ func (c *showControllerCommand) convertMachinesForShow(
 				details.HAPrimary = *m.HAPrimary
 			}
 		}
+		if m.Hardware != nil && m.Hardware.CpuCores != nil {
+			details.CoreCount = new(int)
+			*details.CoreCount = int(*m.Hardware.CpuCores)
+		}
 		nodes[m.Id] = details
 	}
+	if numControllers > 1 {
+		controller.HASummary = haSummary(controllerModel.Machines)
+	}
+}
+
+// haSummary reports how many of the controller members that want a vote
+// currently have one, and whether that's a majority - mirroring how
+// operators reason about mongo replica-set health.
+func haSummary(machines []base.Machine) string {
+	var total, voting, down int
+	for _, m := range machines {
+		if !m.WantsVote {
+			continue
+		}
+		total++
+		if m.Status == string(status.Down) {
+			down++
+			continue
+		}
+		if m.HasVote {
+			voting++
+		}
+	}
+	pending := total - voting - down
+	summary := fmt.Sprintf("%d/%d voting", voting, total)
+	var extra []string
+	if pending > 0 {
+		extra = append(extra, fmt.Sprintf("%d pending", pending))
+	}
+	if down > 0 {
+		extra = append(extra, fmt.Sprintf("%d down", down))
+	}
+	if len(extra) > 0 {
+		summary += ", " + strings.Join(extra, ", ")
+	}
+	if voting*2 <= total {
+		summary += " (NO QUORUM)"
+	} else {
+		summary += " (quorum)"
+	}
+	return summary
 }
 
 func haStatus(hasVote bool, wantsVote bool, statusStr string) string {
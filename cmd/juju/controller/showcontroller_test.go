@@ -5,6 +5,7 @@ package controller_test
 
 import (
 	"regexp"
+	"time"
 
 	"github.com/juju/cmd/v3"
 	"github.com/juju/cmd/v3/cmdtesting"
@@ -16,6 +17,8 @@ import (
 	"github.com/juju/juju/api/base"
 	apicontroller "github.com/juju/juju/api/controller/controller"
 	"github.com/juju/juju/cmd/juju/controller"
+	"github.com/juju/juju/core/instance"
+	"github.com/juju/juju/core/life"
 	"github.com/juju/juju/core/model"
 	"github.com/juju/juju/core/permission"
 	"github.com/juju/juju/jujuclient"
@@ -88,12 +91,17 @@ mallards:
   models:
     controller:
       model-uuid: abc
+      type: iaas
       machine-count: 2
       core-count: 4
     my-model:
       model-uuid: def
+      type: iaas
       machine-count: 2
       core-count: 4
+  totals:
+    machine-count: 4
+    core-count: 8
   current-model: admin/my-model
   account:
     user: admin
@@ -103,6 +111,100 @@ mallards:
 	s.assertShowController(c, "mallards")
 }
 
+func (s *ShowControllerSuite) TestShowControllerModelLife(c *gc.C) {
+	s.controllersYaml = `controllers:
+  mallards:
+    uuid: this-is-another-uuid
+    api-endpoints: [this-is-another-of-many-api-endpoints, this-is-one-more-of-many-api-endpoints]
+    cloud: mallards
+    agent-version: 999.99.99
+    mongo-version: 3.5.12
+    ca-cert: this-is-another-ca-cert
+`
+	s.createTestClientStore(c)
+	s.fakeController.lives = map[string]life.Value{"abc": life.Alive, "def": life.Dying}
+
+	s.expectedOutput = `
+mallards:
+  details:
+    controller-uuid: this-is-another-uuid
+    api-endpoints: [this-is-another-of-many-api-endpoints, this-is-one-more-of-many-api-endpoints]
+    cloud: mallards
+    agent-version: 999.99.99
+    agent-git-commit: badf00d0badf00d0badf00d0badf00d0badf00d0
+    controller-model-version: 999.99.99
+    mongo-version: 3.5.12
+    ca-cert: this-is-another-ca-cert
+  models:
+    controller:
+      model-uuid: abc
+      type: iaas
+      life: alive
+      machine-count: 2
+      core-count: 4
+    my-model:
+      model-uuid: def
+      type: iaas
+      life: dying
+      machine-count: 2
+      core-count: 4
+  totals:
+    machine-count: 4
+    core-count: 8
+  current-model: admin/my-model
+  account:
+    user: admin
+    access: superuser
+`[1:]
+
+	s.assertShowController(c, "mallards")
+}
+
+func (s *ShowControllerSuite) TestShowControllerIncludeDisabledFalse(c *gc.C) {
+	s.controllersYaml = `controllers:
+  mallards:
+    uuid: this-is-another-uuid
+    api-endpoints: [this-is-another-of-many-api-endpoints, this-is-one-more-of-many-api-endpoints]
+    cloud: mallards
+    agent-version: 999.99.99
+    mongo-version: 3.5.12
+    ca-cert: this-is-another-ca-cert
+`
+	s.createTestClientStore(c)
+	s.fakeController.lives = map[string]life.Value{"abc": life.Alive, "def": life.Dying}
+
+	s.expectedOutput = `
+mallards:
+  details:
+    controller-uuid: this-is-another-uuid
+    api-endpoints: [this-is-another-of-many-api-endpoints, this-is-one-more-of-many-api-endpoints]
+    cloud: mallards
+    agent-version: 999.99.99
+    agent-git-commit: badf00d0badf00d0badf00d0badf00d0badf00d0
+    controller-model-version: 999.99.99
+    mongo-version: 3.5.12
+    ca-cert: this-is-another-ca-cert
+  models:
+    controller:
+      model-uuid: abc
+      type: iaas
+      life: alive
+      machine-count: 2
+      core-count: 4
+  totals:
+    machine-count: 4
+    core-count: 8
+  current-model: admin/my-model
+  account:
+    user: admin
+    access: superuser
+`[1:]
+
+	// The dying model is still counted in totals but hidden from the
+	// per-model listing.
+	s.assertShowController(c, "--include-disabled=false", "mallards")
+}
+
 func (s *ShowControllerSuite) TestShowK8sController(c *gc.C) {
 	s.createTestClientStore(c)
 	s.expectedOutput = `
@@ -123,8 +225,10 @@ k8s-controller:
   models:
     controller:
       model-uuid: xyz
+      type: caas
     my-k8s-model:
       model-uuid: def
+      type: caas
       unit-count: 4
   current-model: admin/my-k8s-model
   account:
@@ -161,12 +265,17 @@ mallards:
   models:
     controller:
       model-uuid: abc
+      type: iaas
       machine-count: 2
       core-count: 4
     my-model:
       model-uuid: def
+      type: iaas
       machine-count: 2
       core-count: 4
+  totals:
+    machine-count: 4
+    core-count: 8
   current-model: admin/my-model
   account:
     user: admin
@@ -217,12 +326,17 @@ mallards:
   models:
     controller:
       model-uuid: abc
+      type: iaas
       machine-count: 2
       core-count: 4
     my-model:
       model-uuid: def
+      type: iaas
       machine-count: 2
       core-count: 4
+  totals:
+    machine-count: 4
+    core-count: 8
   current-model: admin/my-model
   account:
     user: admin
@@ -232,6 +346,25 @@ mallards:
 	s.assertShowController(c, "mallards")
 }
 
+func (s *ShowControllerSuite) TestShowControllerTotalsJson(c *gc.C) {
+	s.controllersYaml = `controllers:
+  mallards:
+    uuid: this-is-another-uuid
+    api-endpoints: [this-is-another-of-many-api-endpoints, this-is-one-more-of-many-api-endpoints]
+    cloud: mallards
+    agent-version: 999.99.99
+    mongo-version: 3.5.12
+    ca-cert: this-is-another-ca-cert
+`
+	s.createTestClientStore(c)
+
+	s.expectedOutput = `
+{"mallards":{"details":{"uuid":"this-is-another-uuid","api-endpoints":["this-is-another-of-many-api-endpoints","this-is-one-more-of-many-api-endpoints"],"cloud":"mallards","agent-version":"999.99.99","agent-git-commit":"badf00d0badf00d0badf00d0badf00d0badf00d0","controller-model-version":"999.99.99","mongo-version":"3.5.12","ca-cert":"this-is-another-ca-cert"},"models":{"controller":{"uuid":"abc","type":"iaas","machine-count":2,"core-count":4},"my-model":{"uuid":"def","type":"iaas","machine-count":2,"core-count":4}},"totals":{"machine-count":4,"core-count":8},"current-model":"admin/my-model","account":{"user":"admin","access":"superuser"}}}
+`[1:]
+
+	s.assertShowController(c, "--format", "json", "mallards")
+}
+
 func (s *ShowControllerSuite) TestShowOneControllerManyInStore(c *gc.C) {
 	s.createTestClientStore(c)
 
@@ -257,9 +390,148 @@ aws-test:
     "2":
       instance-id: id-2
       ha-status: ha-enabled
+  ha-summary: 1/3 voting, 1 pending, 1 down (NO QUORUM)
   models:
     controller:
       model-uuid: ghi
+      type: iaas
+      machine-count: 2
+      core-count: 4
+  current-model: admin/controller
+  account:
+    user: admin
+    access: superuser
+`[1:]
+	s.assertShowController(c, "aws-test")
+}
+
+func (s *ShowControllerSuite) TestShowControllerHASummaryQuorum(c *gc.C) {
+	s.createTestClientStore(c)
+	s.fakeController.machines["ghi"] = []base.Machine{
+		{Id: "0", InstanceId: "id-0", HasVote: true, WantsVote: true, Status: "active"},
+		{Id: "1", InstanceId: "id-1", HasVote: true, WantsVote: true, Status: "active"},
+		{Id: "2", InstanceId: "id-2", HasVote: true, WantsVote: true, Status: "active"},
+	}
+
+	s.expectedOutput = `
+aws-test:
+  details:
+    controller-uuid: this-is-the-aws-test-uuid
+    api-endpoints: [this-is-aws-test-of-many-api-endpoints]
+    cloud: aws
+    region: us-east-1
+    agent-version: 999.99.99
+    agent-git-commit: badf00d0badf00d0badf00d0badf00d0badf00d0
+    controller-model-version: 999.99.99
+    mongo-version: 3.5.12
+    ca-cert: this-is-aws-test-ca-cert
+  controller-machines:
+    "0":
+      instance-id: id-0
+      ha-status: ha-enabled
+    "1":
+      instance-id: id-1
+      ha-status: ha-enabled
+    "2":
+      instance-id: id-2
+      ha-status: ha-enabled
+  ha-summary: 3/3 voting (quorum)
+  models:
+    controller:
+      model-uuid: ghi
+      type: iaas
+      machine-count: 2
+      core-count: 4
+  current-model: admin/controller
+  account:
+    user: admin
+    access: superuser
+`[1:]
+	s.assertShowController(c, "aws-test")
+}
+
+func (s *ShowControllerSuite) TestShowControllerMongoVersionOutdatedYaml(c *gc.C) {
+	s.createTestClientStore(c)
+	s.fakeController.mongoVersion = "3.2.9"
+
+	s.expectedOutput = `
+aws-test:
+  details:
+    controller-uuid: this-is-the-aws-test-uuid
+    api-endpoints: [this-is-aws-test-of-many-api-endpoints]
+    cloud: aws
+    region: us-east-1
+    agent-version: 999.99.99
+    agent-git-commit: badf00d0badf00d0badf00d0badf00d0badf00d0
+    controller-model-version: 999.99.99
+    mongo-version: 3.2.9 (upgrade recommended)
+    ca-cert: this-is-aws-test-ca-cert
+  controller-machines:
+    "0":
+      instance-id: id-0
+      ha-status: ha-pending
+    "1":
+      instance-id: id-1
+      ha-status: down, lost connection
+    "2":
+      instance-id: id-2
+      ha-status: ha-enabled
+  ha-summary: 1/3 voting, 1 pending, 1 down (NO QUORUM)
+  models:
+    controller:
+      model-uuid: ghi
+      type: iaas
+      machine-count: 2
+      core-count: 4
+  current-model: admin/controller
+  account:
+    user: admin
+    access: superuser
+`[1:]
+	s.assertShowController(c, "aws-test")
+}
+
+func (s *ShowControllerSuite) TestShowControllerMongoVersionOutdatedJson(c *gc.C) {
+	s.createTestClientStore(c)
+	s.fakeController.mongoVersion = "3.2.9"
+
+	s.expectedOutput = `
+{"aws-test":{"details":{"uuid":"this-is-the-aws-test-uuid","api-endpoints":["this-is-aws-test-of-many-api-endpoints"],"cloud":"aws","region":"us-east-1","agent-version":"999.99.99","agent-git-commit":"badf00d0badf00d0badf00d0badf00d0badf00d0","controller-model-version":"999.99.99","mongo-version":"3.2.9","mongo-upgrade-recommended":true,"ca-cert":"this-is-aws-test-ca-cert"},"controller-machines":{"0":{"instance-id":"id-0","ha-status":"ha-pending"},"1":{"instance-id":"id-1","ha-status":"down, lost connection"},"2":{"instance-id":"id-2","ha-status":"ha-enabled"}},"ha-summary":"1/3 voting, 1 pending, 1 down (NO QUORUM)","models":{"controller":{"uuid":"ghi","type":"iaas","machine-count":2,"core-count":4}},"current-model":"admin/controller","account":{"user":"admin","access":"superuser"}}}
+`[1:]
+	s.assertShowController(c, "--format", "json", "aws-test")
+}
+
+func (s *ShowControllerSuite) TestShowControllerMongoVersionCurrentNotFlagged(c *gc.C) {
+	s.createTestClientStore(c)
+	s.fakeController.mongoVersion = "4.4.10"
+
+	s.expectedOutput = `
+aws-test:
+  details:
+    controller-uuid: this-is-the-aws-test-uuid
+    api-endpoints: [this-is-aws-test-of-many-api-endpoints]
+    cloud: aws
+    region: us-east-1
+    agent-version: 999.99.99
+    agent-git-commit: badf00d0badf00d0badf00d0badf00d0badf00d0
+    controller-model-version: 999.99.99
+    mongo-version: 4.4.10
+    ca-cert: this-is-aws-test-ca-cert
+  controller-machines:
+    "0":
+      instance-id: id-0
+      ha-status: ha-pending
+    "1":
+      instance-id: id-1
+      ha-status: down, lost connection
+    "2":
+      instance-id: id-2
+      ha-status: ha-enabled
+  ha-summary: 1/3 voting, 1 pending, 1 down (NO QUORUM)
+  models:
+    controller:
+      model-uuid: ghi
+      type: iaas
       machine-count: 2
       core-count: 4
   current-model: admin/controller
@@ -294,9 +566,11 @@ aws-test:
     "2":
       instance-id: id-2
       ha-status: ha-enabled
+  ha-summary: 1/3 voting, 1 pending, 1 down (NO QUORUM)
   models:
     controller:
       model-uuid: ghi
+      type: iaas
       machine-count: 2
       core-count: 4
   current-model: admin/controller
@@ -325,7 +599,7 @@ func (s *ShowControllerSuite) TestShowControllerJsonOne(c *gc.C) {
 	s.createTestClientStore(c)
 
 	s.expectedOutput = `
-{"aws-test":{"details":{"uuid":"this-is-the-aws-test-uuid","api-endpoints":["this-is-aws-test-of-many-api-endpoints"],"cloud":"aws","region":"us-east-1","agent-version":"999.99.99","agent-git-commit":"badf00d0badf00d0badf00d0badf00d0badf00d0","controller-model-version":"999.99.99","mongo-version":"3.5.12","ca-cert":"this-is-aws-test-ca-cert"},"controller-machines":{"0":{"instance-id":"id-0","ha-status":"ha-pending"},"1":{"instance-id":"id-1","ha-status":"down, lost connection"},"2":{"instance-id":"id-2","ha-status":"ha-enabled"}},"models":{"controller":{"uuid":"ghi","machine-count":2,"core-count":4}},"current-model":"admin/controller","account":{"user":"admin","access":"superuser"}}}
+{"aws-test":{"details":{"uuid":"this-is-the-aws-test-uuid","api-endpoints":["this-is-aws-test-of-many-api-endpoints"],"cloud":"aws","region":"us-east-1","agent-version":"999.99.99","agent-git-commit":"badf00d0badf00d0badf00d0badf00d0badf00d0","controller-model-version":"999.99.99","mongo-version":"3.5.12","ca-cert":"this-is-aws-test-ca-cert"},"controller-machines":{"0":{"instance-id":"id-0","ha-status":"ha-pending"},"1":{"instance-id":"id-1","ha-status":"down, lost connection"},"2":{"instance-id":"id-2","ha-status":"ha-enabled"}},"ha-summary":"1/3 voting, 1 pending, 1 down (NO QUORUM)","models":{"controller":{"uuid":"ghi","type":"iaas","machine-count":2,"core-count":4}},"current-model":"admin/controller","account":{"user":"admin","access":"superuser"}}}
 `[1:]
 
 	s.assertShowController(c, "--format", "json", "aws-test")
@@ -334,11 +608,143 @@ func (s *ShowControllerSuite) TestShowControllerJsonOne(c *gc.C) {
 func (s *ShowControllerSuite) TestShowControllerJsonMany(c *gc.C) {
 	s.createTestClientStore(c)
 	s.expectedOutput = `
-{"aws-test":{"details":{"uuid":"this-is-the-aws-test-uuid","api-endpoints":["this-is-aws-test-of-many-api-endpoints"],"cloud":"aws","region":"us-east-1","agent-version":"999.99.99","agent-git-commit":"badf00d0badf00d0badf00d0badf00d0badf00d0","controller-model-version":"999.99.99","mongo-version":"3.5.12","ca-cert":"this-is-aws-test-ca-cert"},"controller-machines":{"0":{"instance-id":"id-0","ha-status":"ha-pending"},"1":{"instance-id":"id-1","ha-status":"down, lost connection"},"2":{"instance-id":"id-2","ha-status":"ha-enabled"}},"models":{"controller":{"uuid":"ghi","machine-count":2,"core-count":4}},"current-model":"admin/controller","account":{"user":"admin","access":"superuser"}},"mark-test-prodstack":{"details":{"uuid":"this-is-a-uuid","api-endpoints":["this-is-one-of-many-api-endpoints"],"cloud":"prodstack","agent-version":"999.99.99","agent-git-commit":"badf00d0badf00d0badf00d0badf00d0badf00d0","controller-model-version":"999.99.99","mongo-version":"3.5.12","ca-cert":"this-is-a-ca-cert"},"account":{"user":"admin","access":"superuser"}}}
+{"aws-test":{"details":{"uuid":"this-is-the-aws-test-uuid","api-endpoints":["this-is-aws-test-of-many-api-endpoints"],"cloud":"aws","region":"us-east-1","agent-version":"999.99.99","agent-git-commit":"badf00d0badf00d0badf00d0badf00d0badf00d0","controller-model-version":"999.99.99","mongo-version":"3.5.12","ca-cert":"this-is-aws-test-ca-cert"},"controller-machines":{"0":{"instance-id":"id-0","ha-status":"ha-pending"},"1":{"instance-id":"id-1","ha-status":"down, lost connection"},"2":{"instance-id":"id-2","ha-status":"ha-enabled"}},"ha-summary":"1/3 voting, 1 pending, 1 down (NO QUORUM)","models":{"controller":{"uuid":"ghi","type":"iaas","machine-count":2,"core-count":4}},"current-model":"admin/controller","account":{"user":"admin","access":"superuser"}},"mark-test-prodstack":{"details":{"uuid":"this-is-a-uuid","api-endpoints":["this-is-one-of-many-api-endpoints"],"cloud":"prodstack","agent-version":"999.99.99","agent-git-commit":"badf00d0badf00d0badf00d0badf00d0badf00d0","controller-model-version":"999.99.99","mongo-version":"3.5.12","ca-cert":"this-is-a-ca-cert"},"account":{"user":"admin","access":"superuser"}}}
 `[1:]
 	s.assertShowController(c, "--format", "json", "aws-test", "mark-test-prodstack")
 }
 
+func (s *ShowControllerSuite) TestShowControllerTabularOne(c *gc.C) {
+	s.createTestClientStore(c)
+	s.expectedOutput = `
+NAME      CLOUD/REGION   AGENT-VERSION  CURRENT-MODEL     HA-STATUS
+aws-test  aws/us-east-1  999.99.99      admin/controller  1/3 ha-enabled
+`[1:]
+	s.assertShowController(c, "--format", "tabular", "aws-test")
+}
+
+func (s *ShowControllerSuite) TestShowControllerTabularMany(c *gc.C) {
+	s.createTestClientStore(c)
+	s.expectedOutput = `
+NAME                 CLOUD/REGION   AGENT-VERSION  CURRENT-MODEL     HA-STATUS
+aws-test             aws/us-east-1  999.99.99      admin/controller  1/3 ha-enabled
+mark-test-prodstack  prodstack      999.99.99      -                 -
+`[1:]
+	s.assertShowController(c, "--format", "tabular", "aws-test", "mark-test-prodstack")
+}
+
+func (s *ShowControllerSuite) TestShowControllerAccountLastConnectionYaml(c *gc.C) {
+	s.createTestClientStore(c)
+	when := time.Now().Add(-48 * time.Hour)
+	s.fakeController.lastConnection = &when
+	s.expectedOutput = (`
+aws-test:
+  details:
+    controller-uuid: this-is-the-aws-test-uuid
+    api-endpoints: [this-is-aws-test-of-many-api-endpoints]
+    cloud: aws
+    region: us-east-1
+    agent-version: 999.99.99
+    agent-git-commit: badf00d0badf00d0badf00d0badf00d0badf00d0
+    controller-model-version: 999.99.99
+    mongo-version: 3.5.12
+    ca-cert: this-is-aws-test-ca-cert
+  controller-machines:
+    "0":
+      instance-id: id-0
+      ha-status: ha-pending
+    "1":
+      instance-id: id-1
+      ha-status: down, lost connection
+    "2":
+      instance-id: id-2
+      ha-status: ha-enabled
+  ha-summary: 1/3 voting, 1 pending, 1 down (NO QUORUM)
+  models:
+    controller:
+      model-uuid: ghi
+      type: iaas
+      machine-count: 2
+      core-count: 4
+  current-model: admin/controller
+  account:
+    user: admin
+    access: superuser
+    last-connection: "` + when.Format("2006-01-02") + `"
+`)[1:]
+
+	s.assertShowController(c, "aws-test")
+}
+
+func (s *ShowControllerSuite) TestShowControllerAccountLastConnectionJson(c *gc.C) {
+	s.createTestClientStore(c)
+	when := time.Now().Add(-48 * time.Hour)
+	s.fakeController.lastConnection = &when
+	s.expectedOutput = (`
+{"aws-test":{"details":{"uuid":"this-is-the-aws-test-uuid","api-endpoints":["this-is-aws-test-of-many-api-endpoints"],"cloud":"aws","region":"us-east-1","agent-version":"999.99.99","agent-git-commit":"badf00d0badf00d0badf00d0badf00d0badf00d0","controller-model-version":"999.99.99","mongo-version":"3.5.12","ca-cert":"this-is-aws-test-ca-cert"},"controller-machines":{"0":{"instance-id":"id-0","ha-status":"ha-pending"},"1":{"instance-id":"id-1","ha-status":"down, lost connection"},"2":{"instance-id":"id-2","ha-status":"ha-enabled"}},"ha-summary":"1/3 voting, 1 pending, 1 down (NO QUORUM)","models":{"controller":{"uuid":"ghi","type":"iaas","machine-count":2,"core-count":4}},"current-model":"admin/controller","account":{"user":"admin","access":"superuser","last-connection":"` + when.UTC().Format(time.RFC3339) + `"}}}
+`)[1:]
+
+	s.assertShowController(c, "--format", "json", "aws-test")
+}
+
+func (s *ShowControllerSuite) TestShowControllerModelsOnlyYaml(c *gc.C) {
+	s.createTestClientStore(c)
+	s.expectedOutput = `
+aws-test:
+  models:
+    controller:
+      model-uuid: ghi
+      type: iaas
+      machine-count: 2
+      core-count: 4
+  current-model: admin/controller
+`[1:]
+	s.assertShowController(c, "--models-only", "aws-test")
+}
+
+func (s *ShowControllerSuite) TestShowControllerModelsOnlyJson(c *gc.C) {
+	s.createTestClientStore(c)
+	s.expectedOutput = `
+{"aws-test":{"models":{"controller":{"uuid":"ghi","type":"iaas","machine-count":2,"core-count":4}},"current-model":"admin/controller"}}
+`[1:]
+	s.assertShowController(c, "--models-only", "--format", "json", "aws-test")
+}
+
+func (s *ShowControllerSuite) TestShowControllerModelsOnlyAndShowPasswordMutuallyExclusive(c *gc.C) {
+	s.createTestClientStore(c)
+	s.expectedErr = "--models-only and --show-password are mutually exclusive"
+	s.assertShowControllerFailed(c, "--models-only", "--show-password", "aws-test")
+}
+
+func (s *ShowControllerSuite) TestShowControllerListModelUUIDsOneJson(c *gc.C) {
+	s.createTestClientStore(c)
+	s.expectedOutput = `
+["aws-test:admin/controller:ghi"]
+`[1:]
+	s.assertShowController(c, "--format", "json", "--list-model-uuids", "aws-test")
+}
+
+func (s *ShowControllerSuite) TestShowControllerListModelUUIDsOneYaml(c *gc.C) {
+	s.createTestClientStore(c)
+	s.expectedOutput = `
+- aws-test:admin/controller:ghi
+`[1:]
+	s.assertShowController(c, "--list-model-uuids", "aws-test")
+}
+
+func (s *ShowControllerSuite) TestShowControllerListModelUUIDsMany(c *gc.C) {
+	s.createTestClientStore(c)
+	s.expectedOutput = `
+["aws-test:admin/controller:ghi","mallards:admin/controller:abc","mallards:admin/my-model:def"]
+`[1:]
+	s.assertShowController(c, "--format", "json", "--list-model-uuids", "aws-test", "mallards")
+}
+
+func (s *ShowControllerSuite) TestShowControllerListModelUUIDsAndModelsOnlyMutuallyExclusive(c *gc.C) {
+	s.createTestClientStore(c)
+	s.expectedErr = "--list-model-uuids cannot be used with --models-only, --show-password, --ca-fingerprint-only or --no-refresh"
+	s.assertShowControllerFailed(c, "--list-model-uuids", "--models-only", "aws-test")
+}
+
 func (s *ShowControllerSuite) TestShowControllerReadFromStoreErr(c *gc.C) {
 	s.createTestClientStore(c)
 
@@ -357,7 +763,7 @@ func (s *ShowControllerSuite) TestShowControllerNoArgs(c *gc.C) {
 	store.CurrentControllerName = "aws-test"
 
 	s.expectedOutput = `
-{"aws-test":{"details":{"uuid":"this-is-the-aws-test-uuid","api-endpoints":["this-is-aws-test-of-many-api-endpoints"],"cloud":"aws","region":"us-east-1","agent-version":"999.99.99","agent-git-commit":"badf00d0badf00d0badf00d0badf00d0badf00d0","controller-model-version":"999.99.99","mongo-version":"3.5.12","ca-cert":"this-is-aws-test-ca-cert"},"controller-machines":{"0":{"instance-id":"id-0","ha-status":"ha-pending"},"1":{"instance-id":"id-1","ha-status":"down, lost connection"},"2":{"instance-id":"id-2","ha-status":"ha-enabled"}},"models":{"controller":{"uuid":"ghi","machine-count":2,"core-count":4}},"current-model":"admin/controller","account":{"user":"admin","access":"superuser"}}}
+{"aws-test":{"details":{"uuid":"this-is-the-aws-test-uuid","api-endpoints":["this-is-aws-test-of-many-api-endpoints"],"cloud":"aws","region":"us-east-1","agent-version":"999.99.99","agent-git-commit":"badf00d0badf00d0badf00d0badf00d0badf00d0","controller-model-version":"999.99.99","mongo-version":"3.5.12","ca-cert":"this-is-aws-test-ca-cert"},"controller-machines":{"0":{"instance-id":"id-0","ha-status":"ha-pending"},"1":{"instance-id":"id-1","ha-status":"down, lost connection"},"2":{"instance-id":"id-2","ha-status":"ha-enabled"}},"ha-summary":"1/3 voting, 1 pending, 1 down (NO QUORUM)","models":{"controller":{"uuid":"ghi","type":"iaas","machine-count":2,"core-count":4}},"current-model":"admin/controller","account":{"user":"admin","access":"superuser"}}}
 `[1:]
 	s.assertShowController(c, "--format", "json")
 }
@@ -369,6 +775,26 @@ func (s *ShowControllerSuite) TestShowControllerNoArgsNoCurrent(c *gc.C) {
 	s.assertShowControllerFailed(c)
 }
 
+func (s *ShowControllerSuite) TestShowControllerNoArgsEnvVar(c *gc.C) {
+	store := s.createTestClientStore(c)
+	store.CurrentControllerName = ""
+	s.PatchEnvironment("JUJU_CONTROLLER", "aws-test")
+
+	s.expectedOutput = `
+{"aws-test":{"details":{"uuid":"this-is-the-aws-test-uuid","api-endpoints":["this-is-aws-test-of-many-api-endpoints"],"cloud":"aws","region":"us-east-1","agent-version":"999.99.99","agent-git-commit":"badf00d0badf00d0badf00d0badf00d0badf00d0","controller-model-version":"999.99.99","mongo-version":"3.5.12","ca-cert":"this-is-aws-test-ca-cert"},"controller-machines":{"0":{"instance-id":"id-0","ha-status":"ha-pending"},"1":{"instance-id":"id-1","ha-status":"down, lost connection"},"2":{"instance-id":"id-2","ha-status":"ha-enabled"}},"ha-summary":"1/3 voting, 1 pending, 1 down (NO QUORUM)","models":{"controller":{"uuid":"ghi","type":"iaas","machine-count":2,"core-count":4}},"current-model":"admin/controller","account":{"user":"admin","access":"superuser"}}}
+`[1:]
+	s.assertShowController(c, "--format", "json")
+}
+
+func (s *ShowControllerSuite) TestShowControllerNoArgsEnvVarNotFound(c *gc.C) {
+	store := s.createTestClientStore(c)
+	store.CurrentControllerName = ""
+	s.PatchEnvironment("JUJU_CONTROLLER", "whoops")
+
+	s.expectedErr = `controller whoops not found`
+	s.assertShowControllerFailed(c)
+}
+
 func (s *ShowControllerSuite) TestShowControllerNotFound(c *gc.C) {
 	s.createTestClientStore(c)
 
@@ -418,6 +844,58 @@ func (s *ShowControllerSuite) TestShowControllerRefreshesStoreModels(c *gc.C) {
 	})
 }
 
+func (s *ShowControllerSuite) TestShowControllerNoRefreshNoAPICalls(c *gc.C) {
+	s.createTestClientStore(c)
+	s.api = func(controllerName string) controller.ControllerAccessAPI {
+		c.Fatal("--no-refresh must not contact the controller")
+		return nil
+	}
+
+	_, err := s.runShowController(c, "mallards", "--no-refresh")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ShowControllerSuite) TestShowControllerNoRefresh(c *gc.C) {
+	s.createTestClientStore(c)
+
+	s.expectedOutput = `
+mallards:
+  details:
+    controller-uuid: deadbeef-1bad-500d-9000-4b1d0d06f00d
+    api-endpoints: [this-is-another-of-many-api-endpoints, this-is-one-more-of-many-api-endpoints]
+    cloud: mallards
+    region: mallards1
+    ca-fingerprint: 93:D9:8E:B8:99:36:E8:8E:23:D5:95:5E:81:29:80:B2:D2:89:A7:38:20:7B:1B:BD:96:C8:D9:C1:03:88:55:70
+    ca-cert: |-
+      -----BEGIN CERTIFICATE-----
+      MIICHDCCAcagAwIBAgIUfzWn5ktGMxD6OiTgfiZyvKdM+ZYwDQYJKoZIhvcNAQEL
+      BQAwazENMAsGA1UEChMEanVqdTEzMDEGA1UEAwwqanVqdS1nZW5lcmF0ZWQgQ0Eg
+      Zm9yIG1vZGVsICJqdWp1IHRlc3RpbmciMSUwIwYDVQQFExwxMjM0LUFCQ0QtSVMt
+      Tk9ULUEtUkVBTC1VVUlEMB4XDTE2MDkyMTEwNDgyN1oXDTI2MDkyODEwNDgyN1ow
+      azENMAsGA1UEChMEanVqdTEzMDEGA1UEAwwqanVqdS1nZW5lcmF0ZWQgQ0EgZm9y
+      IG1vZGVsICJqdWp1IHRlc3RpbmciMSUwIwYDVQQFExwxMjM0LUFCQ0QtSVMtTk9U
+      LUEtUkVBTC1VVUlEMFwwDQYJKoZIhvcNAQEBBQADSwAwSAJBAL+0X+1zl2vt1wI4
+      1Q+RnlltJyaJmtwCbHRhREXVGU7t0kTMMNERxqLnuNUyWRz90Rg8s9XvOtCqNYW7
+      mypGrFECAwEAAaNCMEAwDgYDVR0PAQH/BAQDAgKkMA8GA1UdEwEB/wQFMAMBAf8w
+      HQYDVR0OBBYEFHueMLZ1QJ/2sKiPIJ28TzjIMRENMA0GCSqGSIb3DQEBCwUAA0EA
+      ovZN0RbUHrO8q9Eazh0qPO4mwW9jbGTDz126uNrLoz1g3TyWxIas1wRJ8IbCgxLy
+      XUrBZO5UPZab66lJWXyseA==
+      -----END CERTIFICATE-----
+  models:
+    model0:
+      model-uuid: abc
+      type: iaas
+    my-model:
+      model-uuid: def
+      type: iaas
+  current-model: admin/my-model
+  account:
+    user: admin
+    access: superuser
+`[1:]
+	s.assertShowController(c, "mallards", "--no-refresh")
+}
+
 func (s *ShowControllerSuite) TestShowControllerForUserWithLoginAccess(c *gc.C) {
 	s.controllersYaml = `controllers:
   mallards:
@@ -517,12 +995,17 @@ mallards:
   models:
     controller:
       model-uuid: abc
+      type: iaas
       machine-count: 2
       core-count: 4
     my-model:
       model-uuid: def
+      type: iaas
       machine-count: 2
       core-count: 4
+  totals:
+    machine-count: 4
+    core-count: 8
   current-model: admin/my-model
   account:
     user: admin
@@ -532,6 +1015,69 @@ mallards:
 
 	s.assertShowController(c, "mallards", "--show-password")
 }
+
+const caCertYaml = `controllers:
+  mallards:
+    uuid: this-is-another-uuid
+    api-endpoints: [this-is-another-of-many-api-endpoints]
+    cloud: mallards
+    ca-cert: |-
+      -----BEGIN CERTIFICATE-----
+      MIICHDCCAcagAwIBAgIUfzWn5ktGMxD6OiTgfiZyvKdM+ZYwDQYJKoZIhvcNAQEL
+      BQAwazENMAsGA1UEChMEanVqdTEzMDEGA1UEAwwqanVqdS1nZW5lcmF0ZWQgQ0Eg
+      Zm9yIG1vZGVsICJqdWp1IHRlc3RpbmciMSUwIwYDVQQFExwxMjM0LUFCQ0QtSVMt
+      Tk9ULUEtUkVBTC1VVUlEMB4XDTE2MDkyMTEwNDgyN1oXDTI2MDkyODEwNDgyN1ow
+      azENMAsGA1UEChMEanVqdTEzMDEGA1UEAwwqanVqdS1nZW5lcmF0ZWQgQ0EgZm9y
+      IG1vZGVsICJqdWp1IHRlc3RpbmciMSUwIwYDVQQFExwxMjM0LUFCQ0QtSVMtTk9U
+      LUEtUkVBTC1VVUlEMFwwDQYJKoZIhvcNAQEBBQADSwAwSAJBAL+0X+1zl2vt1wI4
+      1Q+RnlltJyaJmtwCbHRhREXVGU7t0kTMMNERxqLnuNUyWRz90Rg8s9XvOtCqNYW7
+      mypGrFECAwEAAaNCMEAwDgYDVR0PAQH/BAQDAgKkMA8GA1UdEwEB/wQFMAMBAf8w
+      HQYDVR0OBBYEFHueMLZ1QJ/2sKiPIJ28TzjIMRENMA0GCSqGSIb3DQEBCwUAA0EA
+      ovZN0RbUHrO8q9Eazh0qPO4mwW9jbGTDz126uNrLoz1g3TyWxIas1wRJ8IbCgxLy
+      XUrBZO5UPZab66lJWXyseA==
+      -----END CERTIFICATE-----
+`
+
+const caCertFingerprint = "93:D9:8E:B8:99:36:E8:8E:23:D5:95:5E:81:29:80:B2:D2:89:A7:38:20:7B:1B:BD:96:C8:D9:C1:03:88:55:70"
+
+func (s *ShowControllerSuite) TestShowControllerCAFingerprintOnly(c *gc.C) {
+	s.controllersYaml = caCertYaml
+	s.createTestClientStore(c)
+
+	s.expectedOutput = "mallards: " + caCertFingerprint + "\n"
+	s.assertShowController(c, "mallards", "--ca-fingerprint-only")
+}
+
+func (s *ShowControllerSuite) TestShowControllerCAFingerprintOnlyNoAPICalls(c *gc.C) {
+	s.controllersYaml = caCertYaml
+	s.createTestClientStore(c)
+	s.api = func(controllerName string) controller.ControllerAccessAPI {
+		c.Fatal("ca-fingerprint-only must not contact the controller")
+		return nil
+	}
+
+	s.expectedOutput = "mallards: " + caCertFingerprint + "\n"
+	s.assertShowController(c, "mallards", "--ca-fingerprint-only")
+}
+
+func (s *ShowControllerSuite) TestShowControllerCAFingerprintOnlyNoCACert(c *gc.C) {
+	s.controllersYaml = `controllers:
+  mallards:
+    uuid: this-is-another-uuid
+    api-endpoints: [this-is-another-of-many-api-endpoints]
+    cloud: mallards
+`
+	s.createTestClientStore(c)
+
+	s.expectedErr = regexp.QuoteMeta(`controller "mallards" has no ca-cert in the client store`)
+	s.assertShowControllerFailed(c, "mallards", "--ca-fingerprint-only")
+}
+
+func (s *ShowControllerSuite) TestShowControllerCAFingerprintOnlyMutuallyExclusive(c *gc.C) {
+	s.expectedErr = `--ca-fingerprint-only cannot be used with --models-only or --show-password`
+	s.assertShowControllerFailed(c, "--ca-fingerprint-only", "--show-password")
+}
+
 func (s *ShowControllerSuite) runShowController(c *gc.C, args ...string) (*cmd.Context, error) {
 	return cmdtesting.RunCommand(c, controller.NewShowControllerCommandForTest(s.store, s.api), args...)
 }
@@ -572,9 +1118,11 @@ aws-test:
       instance-id: id-2
       ha-status: ha-enabled
       ha-primary: true
+  ha-summary: 1/3 voting, 1 pending, 1 down (NO QUORUM)
   models:
     controller:
       model-uuid: ghi
+      type: iaas
       machine-count: 2
       core-count: 4
   current-model: admin/controller
@@ -589,6 +1137,50 @@ aws-test:
 	s.assertShowController(c, "aws-test")
 }
 
+func (s *ShowControllerSuite) TestShowControllerMachineCoreCount(c *gc.C) {
+	_ = s.createTestClientStore(c)
+	s.expectedOutput = `
+aws-test:
+  details:
+    controller-uuid: this-is-the-aws-test-uuid
+    api-endpoints: [this-is-aws-test-of-many-api-endpoints]
+    cloud: aws
+    region: us-east-1
+    agent-version: 999.99.99
+    agent-git-commit: badf00d0badf00d0badf00d0badf00d0badf00d0
+    controller-model-version: 999.99.99
+    mongo-version: 3.5.12
+    ca-cert: this-is-aws-test-ca-cert
+  controller-machines:
+    "0":
+      instance-id: id-0
+      ha-status: ha-pending
+      core-count: 8
+    "1":
+      instance-id: id-1
+      ha-status: down, lost connection
+    "2":
+      instance-id: id-2
+      ha-status: ha-enabled
+  ha-summary: 1/3 voting, 1 pending, 1 down (NO QUORUM)
+  models:
+    controller:
+      model-uuid: ghi
+      type: iaas
+      machine-count: 2
+      core-count: 4
+  current-model: admin/controller
+  account:
+    user: admin
+    access: superuser
+`[1:]
+
+	cores := uint64(8)
+	s.fakeController.machines["ghi"][0].Hardware = &instance.HardwareCharacteristics{CpuCores: &cores}
+
+	s.assertShowController(c, "aws-test")
+}
+
 func (s *ShowControllerSuite) TestShowControllerPrimaryModelStatusFail(c *gc.C) {
 	_ = s.createTestClientStore(c)
 	s.expectedOutput = `
@@ -623,10 +1215,13 @@ type fakeController struct {
 	machines          map[string][]base.Machine
 	units             map[string]int
 	modelTypes        map[string]model.ModelType
+	lives             map[string]life.Value
 	access            permission.Access
 	identityURL       string
 	controllerVersion apicontroller.ControllerVersion
 	emptyModelStatus  bool
+	lastConnection    *time.Time
+	mongoVersion      string
 }
 
 func (c *fakeController) GetControllerAccess(user string) (permission.Access, error) {
@@ -644,6 +1239,7 @@ func (c *fakeController) ModelStatus(models ...names.ModelTag) (result []base.Mo
 	for _, mtag := range models {
 		result = append(result, base.ModelStatus{
 			UUID:              mtag.Id(),
+			Life:              c.lives[mtag.Id()],
 			TotalMachineCount: 2,
 			CoreCount:         4,
 			Machines:          c.machines[mtag.Id()],
@@ -654,7 +1250,14 @@ func (c *fakeController) ModelStatus(models ...names.ModelTag) (result []base.Mo
 	return result, nil
 }
 
+func (c *fakeController) AccountLastConnection(user string) (*time.Time, error) {
+	return c.lastConnection, nil
+}
+
 func (c *fakeController) MongoVersion() (string, error) {
+	if c.mongoVersion != "" {
+		return c.mongoVersion, nil
+	}
 	return "3.5.12", nil
 }
 
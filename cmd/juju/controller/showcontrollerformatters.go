@@ -0,0 +1,67 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package controller
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/cmd/output"
+)
+
+// formatShowControllerTabular returns a tabular summary of the controllers
+// passed in, one row per controller.
+func formatShowControllerTabular(writer io.Writer, value interface{}) error {
+	controllers, ok := value.(map[string]ShowControllerDetails)
+	if !ok {
+		return errors.Errorf("expected value of type %T, got %T", controllers, value)
+	}
+
+	tw := output.TabWriter(writer)
+	w := output.Wrapper{tw}
+	w.Println("NAME", "CLOUD/REGION", "AGENT-VERSION", "CURRENT-MODEL", "HA-STATUS")
+
+	names := make([]string, 0, len(controllers))
+	for name := range controllers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		details := controllers[name]
+		cloudRegion := details.Details.Cloud
+		if details.Details.CloudRegion != "" {
+			cloudRegion += "/" + details.Details.CloudRegion
+		}
+		agentVersion := details.Details.AgentVersion
+		if agentVersion == "" {
+			agentVersion = noValueDisplay
+		}
+		currentModel := details.CurrentModel
+		if currentModel == "" {
+			currentModel = noValueDisplay
+		}
+		w.Println(name, cloudRegion, agentVersion, currentModel, haStatusSummary(details.Machines))
+	}
+	return tw.Flush()
+}
+
+// haStatusSummary reports how many of the controller's machines are
+// ha-enabled, or "-" if the controller has no controller-machines to report
+// (for example, a k8s controller or an older API version).
+func haStatusSummary(machines map[string]MachineDetails) string {
+	if len(machines) == 0 {
+		return noValueDisplay
+	}
+	ready := 0
+	for _, m := range machines {
+		if m.HAStatus == "ha-enabled" {
+			ready++
+		}
+	}
+	return fmt.Sprintf("%d/%d ha-enabled", ready, len(machines))
+}
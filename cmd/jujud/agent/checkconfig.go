@@ -0,0 +1,83 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package agent
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd/v3"
+	"github.com/juju/errors"
+	"github.com/juju/utils/v3/cert"
+
+	"github.com/juju/juju/agent"
+	jujucmd "github.com/juju/juju/cmd"
+	agenterrors "github.com/juju/juju/cmd/jujud/agent/errors"
+)
+
+type checkConfigCommand struct {
+	cmd.CommandBase
+	configPath string
+}
+
+// NewCheckConfigCommand returns a command that validates an agent
+// configuration file without needing to start the agent.
+func NewCheckConfigCommand() cmd.Command {
+	return &checkConfigCommand{}
+}
+
+// Info is part of cmd.Command.
+func (c *checkConfigCommand) Info() *cmd.Info {
+	return jujucmd.Info(&cmd.Info{
+		Name:    "check-config",
+		Args:    "<config-path>",
+		Purpose: "validate an agent configuration file",
+	})
+}
+
+// Init is part of cmd.Command.
+func (c *checkConfigCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return &agenterrors.FatalError{"config-path argument is required"}
+	}
+	configPath, args := args[0], args[1:]
+	if err := cmd.CheckEmpty(args); err != nil {
+		return err
+	}
+	c.configPath = configPath
+	return nil
+}
+
+// Run is part of cmd.Command.
+func (c *checkConfigCommand) Run(ctx *cmd.Context) error {
+	config, err := agent.ReadConfig(c.configPath)
+	if err != nil {
+		return errors.Annotatef(err, "loading agent config %q", c.configPath)
+	}
+
+	if tag := config.Tag(); tag.Id() == "" {
+		return errors.Errorf("agent config has an empty tag")
+	}
+	fmt.Fprintf(ctx.Stdout, "tag: ok (%s)\n", config.Tag())
+
+	addresses, err := config.APIAddresses()
+	if err != nil {
+		return errors.Annotate(err, "reading API addresses")
+	}
+	if len(addresses) == 0 {
+		return errors.Errorf("agent config has no API addresses")
+	}
+	fmt.Fprintf(ctx.Stdout, "api addresses: ok (%s)\n", addresses)
+
+	caCert := config.CACert()
+	if caCert == "" {
+		return errors.Errorf("agent config has no CA certificate")
+	}
+	if _, err := cert.ParseCert(caCert); err != nil {
+		return errors.Annotate(err, "parsing CA certificate")
+	}
+	fmt.Fprintln(ctx.Stdout, "ca certificate: ok")
+
+	fmt.Fprintf(ctx.Stdout, "%s: configuration is valid\n", c.configPath)
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package agent_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/juju/cmd/v3/cmdtesting"
+	"github.com/juju/names/v4"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/agent"
+	agentcmd "github.com/juju/juju/cmd/jujud/agent"
+	jujuversion "github.com/juju/juju/version"
+
+	coretesting "github.com/juju/juju/testing"
+)
+
+type checkConfigSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&checkConfigSuite{})
+
+// writeAgentConfig writes out an agent config file, optionally mutated by
+// tweak, and returns its path.
+func (s *checkConfigSuite) writeAgentConfig(c *gc.C, tweak func(agent.ConfigSetterWriter)) string {
+	dataDir := c.MkDir()
+	conf, err := agent.NewAgentConfig(agent.AgentConfigParams{
+		Paths:             agent.Paths{DataDir: dataDir},
+		Tag:               names.NewMachineTag("0"),
+		UpgradedToVersion: jujuversion.Current,
+		APIAddresses:      []string{"localhost:17070"},
+		CACert:            coretesting.CACert,
+		Password:          coretesting.DefaultMongoPassword,
+		Controller:        coretesting.ControllerTag,
+		Model:             coretesting.ModelTag,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	if tweak != nil {
+		tweak(conf)
+	}
+	c.Assert(conf.Write(), jc.ErrorIsNil)
+	return agent.ConfigPath(dataDir, names.NewMachineTag("0"))
+}
+
+func (s *checkConfigSuite) TestInitRequiresConfigPath(c *gc.C) {
+	cmd := agentcmd.NewCheckConfigCommand()
+	err := cmd.Init(nil)
+	c.Assert(err, gc.ErrorMatches, "config-path argument is required")
+}
+
+func (s *checkConfigSuite) TestInitRejectsExtraArgs(c *gc.C) {
+	cmd := agentcmd.NewCheckConfigCommand()
+	err := cmd.Init([]string{"agent.conf", "extra"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+}
+
+func (s *checkConfigSuite) TestRunGoodConfig(c *gc.C) {
+	path := s.writeAgentConfig(c, nil)
+	cmd := agentcmd.NewCheckConfigCommand()
+	c.Assert(cmd.Init([]string{path}), jc.ErrorIsNil)
+	ctx := cmdtesting.Context(c)
+	err := cmd.Run(ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), jc.Contains, "configuration is valid")
+}
+
+func (s *checkConfigSuite) TestRunMissingFile(c *gc.C) {
+	cmd := agentcmd.NewCheckConfigCommand()
+	path := filepath.Join(c.MkDir(), "missing.conf")
+	c.Assert(cmd.Init([]string{path}), jc.ErrorIsNil)
+	err := cmd.Run(cmdtesting.Context(c))
+	c.Assert(err, gc.ErrorMatches, `loading agent config ".*": cannot read agent config .*`)
+}
+
+func (s *checkConfigSuite) TestRunMalformedFile(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "agent.conf")
+	c.Assert(os.WriteFile(path, []byte("this is not a valid agent config"), 0644), jc.ErrorIsNil)
+	cmd := agentcmd.NewCheckConfigCommand()
+	c.Assert(cmd.Init([]string{path}), jc.ErrorIsNil)
+	err := cmd.Run(cmdtesting.Context(c))
+	c.Assert(err, gc.ErrorMatches, `loading agent config ".*": .*`)
+}
+
+func (s *checkConfigSuite) TestRunBadCACert(c *gc.C) {
+	path := s.writeAgentConfig(c, func(conf agent.ConfigSetterWriter) {
+		conf.SetCACert("not a certificate")
+	})
+
+	cmd := agentcmd.NewCheckConfigCommand()
+	c.Assert(cmd.Init([]string{path}), jc.ErrorIsNil)
+	err := cmd.Run(cmdtesting.Context(c))
+	c.Assert(err, gc.ErrorMatches, "parsing CA certificate: .*")
+}
@@ -5,9 +5,11 @@ package agent
 
 import (
 	"io"
+	"time"
 
 	"github.com/juju/cmd/v3"
 	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
 	"github.com/juju/loggo"
 	"github.com/juju/names/v4"
 
@@ -33,14 +35,19 @@ type checkConnectionCommand struct {
 	agentName string
 	config    agentconf.AgentConf
 	connect   ConnectFunc
+
+	retryCount   int
+	retryDelay   time.Duration
+	retryBackoff bool
 }
 
 // NewCheckConnectionCommand returns a command that will test
 // connecting to the API with details from the agent's config.
 func NewCheckConnectionCommand(config agentconf.AgentConf, connect ConnectFunc) cmd.Command {
 	return &checkConnectionCommand{
-		config:  config,
-		connect: connect,
+		config:     config,
+		connect:    connect,
+		retryDelay: time.Second,
 	}
 }
 
@@ -50,9 +57,22 @@ func (c *checkConnectionCommand) Info() *cmd.Info {
 		Name:    "check-connection",
 		Args:    "<agent-name>",
 		Purpose: "check connection to the API server for the specified agent",
+		Doc: `
+By default a single connection attempt is made. Pass --retry-count to
+retry on failure, waiting --retry-delay between attempts (doubling the
+delay each time if --retry-backoff is set), so the command can be used
+to poll for controller readiness.
+`[1:],
 	})
 }
 
+// SetFlags is part of cmd.Command.
+func (c *checkConnectionCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.IntVar(&c.retryCount, "retry-count", 0, "number of extra attempts to make if the initial connection fails")
+	f.DurationVar(&c.retryDelay, "retry-delay", time.Second, "delay between connection attempts")
+	f.BoolVar(&c.retryBackoff, "retry-backoff", false, "double the delay after each failed attempt")
+}
+
 // Init is part of cmd.Command.
 func (c *checkConnectionCommand) Init(args []string) error {
 	if len(args) == 0 {
@@ -79,12 +99,23 @@ func (c *checkConnectionCommand) Init(args []string) error {
 
 // Run is part of cmd.Command.
 func (c *checkConnectionCommand) Run(ctx *cmd.Context) error {
-	conn, err := c.connect(c.config)
-	if err != nil {
-		return errors.Annotatef(err, "checking connection for %s", c.agentName)
+	delay := c.retryDelay
+	var conn io.Closer
+	var err error
+	for attempt := 0; ; attempt++ {
+		conn, err = c.connect(c.config)
+		if err == nil {
+			break
+		}
+		if attempt >= c.retryCount {
+			return errors.Annotatef(err, "checking connection for %s", c.agentName)
+		}
+		time.Sleep(delay)
+		if c.retryBackoff {
+			delay *= 2
+		}
 	}
-	err = conn.Close()
-	if err != nil {
+	if err = conn.Close(); err != nil {
 		return errors.Annotatef(err, "closing connection for %s", c.agentName)
 	}
 	return nil
@@ -6,6 +6,7 @@ package agent_test
 import (
 	"io"
 
+	"github.com/juju/cmd/v3/cmdtesting"
 	"github.com/juju/errors"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
@@ -54,6 +55,54 @@ func (s *checkConnectionSuite) TestRunClosesConnection(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "closing connection for unit-artemis-5: seal integrity check failed")
 }
 
+func (s *checkConnectionSuite) TestRunSucceedsOnThirdAttempt(c *gc.C) {
+	attempts := 0
+	cmd := agentcmd.NewCheckConnectionCommand(newAgentConf(),
+		func(a agent.Agent) (io.Closer, error) {
+			attempts++
+			if attempts < 3 {
+				return nil, errors.Errorf("not ready yet")
+			}
+			return &mockConnection{ok: true}, nil
+		})
+	err := cmdtesting.InitCommand(cmd, []string{"unit-artemis-5", "--retry-count", "5", "--retry-delay", "1ms"})
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := cmdtesting.Context(c)
+	err = cmd.Run(ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(attempts, gc.Equals, 3)
+}
+
+func (s *checkConnectionSuite) TestRunExhaustsRetries(c *gc.C) {
+	attempts := 0
+	cmd := agentcmd.NewCheckConnectionCommand(newAgentConf(),
+		func(a agent.Agent) (io.Closer, error) {
+			attempts++
+			return nil, errors.Errorf("still not ready")
+		})
+	err := cmdtesting.InitCommand(cmd, []string{"unit-artemis-5", "--retry-count", "2", "--retry-delay", "1ms"})
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := cmdtesting.Context(c)
+	err = cmd.Run(ctx)
+	c.Assert(err, gc.ErrorMatches, "checking connection for unit-artemis-5: still not ready")
+	c.Assert(attempts, gc.Equals, 3)
+}
+
+func (s *checkConnectionSuite) TestRunDefaultsToSingleAttempt(c *gc.C) {
+	attempts := 0
+	cmd := agentcmd.NewCheckConnectionCommand(newAgentConf(),
+		func(a agent.Agent) (io.Closer, error) {
+			attempts++
+			return nil, errors.Errorf("nope")
+		})
+	err := cmdtesting.InitCommand(cmd, []string{"unit-artemis-5"})
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := cmdtesting.Context(c)
+	err = cmd.Run(ctx)
+	c.Assert(err, gc.ErrorMatches, "checking connection for unit-artemis-5: nope")
+	c.Assert(attempts, gc.Equals, 1)
+}
+
 func newAgentConf() *mockAgentConf {
 	return &mockAgentConf{stub: &testing.Stub{}}
 }
@@ -68,8 +117,13 @@ func (c *mockAgentConf) ReadConfig(tag string) error {
 	return c.stub.NextErr()
 }
 
-type mockConnection struct{}
+type mockConnection struct {
+	ok bool
+}
 
 func (c *mockConnection) Close() error {
+	if c.ok {
+		return nil
+	}
 	return errors.Errorf("seal integrity check failed")
 }
@@ -0,0 +1,139 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package agent
+
+import (
+	"github.com/juju/cmd/v3"
+	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
+	"github.com/juju/loggo"
+	"github.com/juju/names/v4"
+	"github.com/juju/utils/v3"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/api"
+	apiagent "github.com/juju/juju/api/agent/agent"
+	"github.com/juju/juju/api/base"
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/jujud/agent/agentconf"
+	agenterrors "github.com/juju/juju/cmd/jujud/agent/errors"
+	"github.com/juju/juju/worker/apicaller"
+)
+
+// RotatePasswordAPI is the subset of api.Connection that the rotate-password
+// command needs: enough to build the Agent facade and close the connection
+// afterwards. It exists so tests can supply a lightweight fake instead of a
+// full api.Connection.
+type RotatePasswordAPI interface {
+	base.APICaller
+	Close() error
+}
+
+// ConnectAPIFunc connects to the API as the given agent, returning the
+// resulting connection.
+type ConnectAPIFunc func(agent.Agent) (RotatePasswordAPI, error)
+
+// ConnectAsAgentAPI really connects to the API specified in the agent
+// config, returning the full api.Connection rather than just an
+// io.Closer. It's extracted so tests can pass something else in.
+func ConnectAsAgentAPI(a agent.Agent) (RotatePasswordAPI, error) {
+	return apicaller.ScaryConnect(a, api.Open, loggo.GetLogger("juju.agent"))
+}
+
+type rotatePasswordCommand struct {
+	cmd.CommandBase
+	agentTag names.Tag
+	config   agentconf.AgentConf
+	connect  ConnectAPIFunc
+
+	newPassword func() (string, error)
+}
+
+// NewRotatePasswordCommand returns a command that generates a new random
+// password for the agent, sets it on the controller, and only then updates
+// the agent's local configuration to match.
+func NewRotatePasswordCommand(config agentconf.AgentConf, connect ConnectAPIFunc) cmd.Command {
+	return &rotatePasswordCommand{
+		config:      config,
+		connect:     connect,
+		newPassword: utils.RandomPassword,
+	}
+}
+
+// Info is part of cmd.Command.
+func (c *rotatePasswordCommand) Info() *cmd.Info {
+	return jujucmd.Info(&cmd.Info{
+		Name:    "rotate-password",
+		Args:    "<agent-name>",
+		Purpose: "change the API password used by the specified agent",
+		Doc: `
+rotate-password connects to the API server as the named agent, sets a
+freshly generated password on the controller, and only writes that
+password to the agent's local configuration once the controller has
+accepted it. If the controller rejects the new password, the agent's
+configuration is left untouched.
+`[1:],
+	})
+}
+
+// SetFlags is part of cmd.Command.
+func (c *rotatePasswordCommand) SetFlags(f *gnuflag.FlagSet) {}
+
+// Init is part of cmd.Command.
+func (c *rotatePasswordCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return &agenterrors.FatalError{Err: "agent-name argument is required"}
+	}
+	agentName, args := args[0], args[1:]
+	if err := cmd.CheckEmpty(args); err != nil {
+		return err
+	}
+	tag, err := names.ParseTag(agentName)
+	if err != nil {
+		return errors.Annotatef(err, "agent-name")
+	}
+	if err := c.config.ReadConfig(agentName); err != nil {
+		return errors.Trace(err)
+	}
+	c.agentTag = tag
+	return nil
+}
+
+// Run is part of cmd.Command.
+func (c *rotatePasswordCommand) Run(ctx *cmd.Context) error {
+	newPassword, err := c.newPassword()
+	if err != nil {
+		return errors.Annotate(err, "generating new password")
+	}
+
+	conn, err := c.connect(c.config)
+	if err != nil {
+		return errors.Annotatef(err, "connecting for %s", c.agentTag)
+	}
+	defer conn.Close()
+
+	agentFacade, err := apiagent.NewState(conn)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	entity, err := agentFacade.Entity(c.agentTag)
+	if err != nil {
+		return errors.Annotatef(err, "retrieving agent entity for %s", c.agentTag)
+	}
+	if err := entity.SetPassword(newPassword); err != nil {
+		return errors.Annotatef(err, "setting new password for %s", c.agentTag)
+	}
+
+	// Only update the local configuration once the controller has
+	// accepted the new password, so a failure above never leaves the
+	// agent unable to authenticate with either the old or new password.
+	err = c.config.ChangeConfig(func(setter agent.ConfigSetter) error {
+		setter.SetPassword(newPassword)
+		return nil
+	})
+	if err != nil {
+		return errors.Annotatef(err, "writing new password to agent configuration for %s", c.agentTag)
+	}
+	return nil
+}
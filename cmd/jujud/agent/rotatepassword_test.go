@@ -0,0 +1,143 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package agent_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names/v4"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/agent"
+	basetesting "github.com/juju/juju/api/base/testing"
+	agentcmd "github.com/juju/juju/cmd/jujud/agent"
+	"github.com/juju/juju/cmd/jujud/agent/agentconf"
+	"github.com/juju/juju/rpc/params"
+	coretesting "github.com/juju/juju/testing"
+	jujuversion "github.com/juju/juju/version"
+)
+
+type rotatePasswordSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&rotatePasswordSuite{})
+
+func (s *rotatePasswordSuite) TestInitChecksTag(c *gc.C) {
+	cmd := agentcmd.NewRotatePasswordCommand(nil, nil)
+	err := cmd.Init(nil)
+	c.Assert(err, gc.ErrorMatches, "agent-name argument is required")
+	err = cmd.Init([]string{"aloy"})
+	c.Assert(err, gc.ErrorMatches, `agent-name: "aloy" is not a valid tag`)
+	err = cmd.Init([]string{"unit-demeter-0", "minerva"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["minerva"\]`)
+}
+
+func (s *rotatePasswordSuite) TestRunSetsPasswordOnControllerThenConfig(c *gc.C) {
+	conf := newFakeAgentConf(c)
+	apiConn := newFakeRotateAPI(nil)
+	cmd := agentcmd.NewRotatePasswordCommand(conf, func(agent.Agent) (agentcmd.RotatePasswordAPI, error) {
+		return apiConn, nil
+	})
+	c.Assert(cmd.Init([]string{"unit-artemis-5"}), jc.ErrorIsNil)
+
+	err := cmd.Run(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(apiConn.closed, jc.IsTrue)
+	c.Assert(apiConn.passwordSet, gc.Not(gc.Equals), "")
+
+	info, ok := conf.config.APIInfo()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(info.Password, gc.Equals, apiConn.passwordSet)
+}
+
+func (s *rotatePasswordSuite) TestRunLeavesConfigUntouchedOnControllerRejection(c *gc.C) {
+	conf := newFakeAgentConf(c)
+	originalPassword, _ := conf.config.APIInfo()
+
+	apiConn := newFakeRotateAPI(&params.Error{Message: "not authorized"})
+	cmd := agentcmd.NewRotatePasswordCommand(conf, func(agent.Agent) (agentcmd.RotatePasswordAPI, error) {
+		return apiConn, nil
+	})
+	c.Assert(cmd.Init([]string{"unit-artemis-5"}), jc.ErrorIsNil)
+
+	err := cmd.Run(nil)
+	c.Assert(err, gc.ErrorMatches, "setting new password for unit-artemis-5: not authorized")
+	c.Assert(apiConn.closed, jc.IsTrue)
+
+	info, ok := conf.config.APIInfo()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(info.Password, gc.Equals, originalPassword.Password)
+}
+
+// fakeAgentConf is an agentconf.AgentConf backed by a real, disk-based agent
+// config, so that ChangeConfig exercises the same write path as production
+// code; only ReadConfig and ChangeConfig are used by rotatePasswordCommand.
+type fakeAgentConf struct {
+	agentconf.AgentConf
+	config agent.ConfigSetterWriter
+}
+
+func newFakeAgentConf(c *gc.C) *fakeAgentConf {
+	conf, err := agent.NewAgentConfig(agent.AgentConfigParams{
+		Paths:             agent.Paths{DataDir: c.MkDir()},
+		Tag:               names.NewUnitTag("artemis/5"),
+		UpgradedToVersion: jujuversion.Current,
+		APIAddresses:      []string{"localhost:17070"},
+		CACert:            coretesting.CACert,
+		Password:          coretesting.DefaultMongoPassword,
+		Controller:        coretesting.ControllerTag,
+		Model:             coretesting.ModelTag,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return &fakeAgentConf{config: conf}
+}
+
+func (f *fakeAgentConf) ReadConfig(tag string) error {
+	return nil
+}
+
+func (f *fakeAgentConf) ChangeConfig(change agent.ConfigMutator) error {
+	if err := change(f.config); err != nil {
+		return err
+	}
+	return f.config.Write()
+}
+
+// fakeRotateAPI is a minimal agentcmd.RotatePasswordAPI that answers the
+// Agent facade's GetEntities and SetPasswords calls directly, without
+// needing a real API connection.
+type fakeRotateAPI struct {
+	basetesting.APICallerFunc
+	closed      bool
+	passwordSet string
+}
+
+func newFakeRotateAPI(setPasswordErr *params.Error) *fakeRotateAPI {
+	f := &fakeRotateAPI{}
+	f.APICallerFunc = func(objType string, version int, id, request string, args, response interface{}) error {
+		switch request {
+		case "GetEntities":
+			*response.(*params.AgentGetEntitiesResults) = params.AgentGetEntitiesResults{
+				Entities: []params.AgentGetEntitiesResult{{}},
+			}
+		case "SetPasswords":
+			if setPasswordErr == nil {
+				f.passwordSet = args.(params.EntityPasswords).Changes[0].Password
+			}
+			*response.(*params.ErrorResults) = params.ErrorResults{
+				Results: []params.ErrorResult{{Error: setPasswordErr}},
+			}
+		default:
+			return errors.Errorf("unexpected request %q", request)
+		}
+		return nil
+	}
+	return f
+}
+
+func (f *fakeRotateAPI) Close() error {
+	f.closed = true
+	return nil
+}
@@ -9,8 +9,11 @@ package dumplogs
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"time"
@@ -21,6 +24,7 @@ import (
 	"github.com/juju/gnuflag"
 	"github.com/juju/loggo"
 	"github.com/juju/names/v4"
+	goyaml "gopkg.in/yaml.v2"
 
 	"github.com/juju/juju/agent"
 	jujucmd "github.com/juju/juju/cmd"
@@ -31,6 +35,17 @@ import (
 	"github.com/juju/juju/state"
 )
 
+// logRecord is the structured, serializable form of a single log
+// message, used by the json and yaml --format options.
+type logRecord struct {
+	Timestamp string `json:"timestamp" yaml:"timestamp"`
+	Model     string `json:"model" yaml:"model"`
+	Entity    string `json:"entity" yaml:"entity"`
+	Level     string `json:"level" yaml:"level"`
+	Module    string `json:"module" yaml:"module"`
+	Message   string `json:"message" yaml:"message"`
+}
+
 // NewCommand returns a new Command instance which implements the
 // "juju-dumplogs" command.
 func NewCommand() cmd.Command {
@@ -41,9 +56,17 @@ func NewCommand() cmd.Command {
 
 type dumpLogsCommand struct {
 	cmd.CommandBase
-	agentConfig agentconf.AgentConf
-	machineId   string
-	outDir      string
+	agentConfig   agentconf.AgentConf
+	machineId     string
+	outDir        string
+	format        string
+	level         string
+	minLevel      loggo.Level
+	since         string
+	startTime     time.Time
+	includeModule []string
+	excludeModule []string
+	follow        bool
 }
 
 // Info implements cmd.Command.
@@ -62,6 +85,20 @@ In order to connect to the database, the local machine agent's
 configuration is needed. In most circumstances the configuration will
 be found automatically. The --data-dir and/or --machine-id options may
 be required if the agent configuration can't be found automatically.
+
+The --level, --since, --include-module and --exclude-module options
+filter the records written out. --level sets a minimum log level; --since
+sets a minimum timestamp, accepting either an RFC3339 timestamp or a
+relative duration such as "30m" (meaning 30 minutes before now); the
+module options match a (dotted) logging module name or any of its
+children. All supplied filters must match for a record to be included.
+
+The --follow option keeps the output file open after the existing
+records have been written, and streams new records as they are added to
+the database, until interrupted. Models are followed one at a time: a
+model's output file is only started once the previous model's stream
+has ended, and an interrupt ends the command without writing the
+remaining models.
 `[1:]
 	return jujucmd.Info(&cmd.Info{
 		Name:    corenames.JujuDumpLogs,
@@ -76,10 +113,39 @@ func (c *dumpLogsCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.outDir, "d", ".", "directory to write logs files to")
 	f.StringVar(&c.outDir, "output-directory", ".", "")
 	f.StringVar(&c.machineId, "machine-id", "", "id of the machine on this host (optional)")
+	f.StringVar(&c.format, "format", "text", "output format (text|json|yaml)")
+	f.StringVar(&c.level, "level", "", "minimum log level to include, one of [TRACE, DEBUG, INFO, WARNING, ERROR]")
+	f.StringVar(&c.since, "since", "", "only include records at or after this time (RFC3339 timestamp or relative duration such as \"30m\")")
+	f.Var(cmd.NewAppendStringsValue(&c.includeModule), "include-module", "only include log messages for these (dotted-prefix) logging modules")
+	f.Var(cmd.NewAppendStringsValue(&c.excludeModule), "exclude-module", "exclude log messages for these (dotted-prefix) logging modules")
+	f.BoolVar(&c.follow, "follow", false, "keep streaming new log records as they arrive")
 }
 
 // Init implements cmd.Command.
 func (c *dumpLogsCommand) Init(args []string) error {
+	switch c.format {
+	case "text", "json", "yaml":
+	default:
+		return errors.Errorf("invalid format %q, expected one of text, json, yaml", c.format)
+	}
+
+	if c.level != "" {
+		level, ok := loggo.ParseLevel(c.level)
+		if !ok || level < loggo.TRACE || level > loggo.ERROR {
+			return errors.Errorf("level value %q is not one of %q, %q, %q, %q, %q",
+				c.level, loggo.TRACE, loggo.DEBUG, loggo.INFO, loggo.WARNING, loggo.ERROR)
+		}
+		c.minLevel = level
+	}
+
+	if c.since != "" {
+		startTime, err := parseSince(c.since, time.Now())
+		if err != nil {
+			return errors.Annotate(err, "invalid --since value")
+		}
+		c.startTime = startTime
+	}
+
 	err := c.agentConfig.CheckArgs(args)
 	if err != nil {
 		return errors.Trace(err)
@@ -137,16 +203,48 @@ func (c *dumpLogsCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return errors.Annotate(err, "failed to look up models")
 	}
+	var stop <-chan struct{}
+	if c.follow {
+		interrupted := make(chan os.Signal, 1)
+		signal.Notify(interrupted, os.Interrupt)
+		defer signal.Stop(interrupted)
+
+		stopped := make(chan struct{})
+		go func() {
+			<-interrupted
+			close(stopped)
+		}()
+		stop = stopped
+	}
+
 	for _, modelUUID := range modelUUIDs {
-		err := c.dumpLogsForEnv(ctx, statePool, names.NewModelTag(modelUUID))
+		err := c.dumpLogsForEnv(ctx, statePool, names.NewModelTag(modelUUID), stop)
 		if err != nil {
 			return errors.Annotatef(err, "failed to dump logs for model %s", modelUUID)
 		}
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
 	}
 
 	return nil
 }
 
+// parseSince interprets s as either an RFC3339 timestamp or a duration
+// (as accepted by time.ParseDuration, e.g. "30m") measured back from now.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, errors.Errorf("%q is not an RFC3339 timestamp or a duration", s)
+	}
+	return t, nil
+}
+
 func (c *dumpLogsCommand) findAgentTag(dataDir string) (names.Tag, error) {
 	entries, err := os.ReadDir(agent.BaseDir(dataDir))
 	if err != nil {
@@ -168,7 +266,7 @@ func (c *dumpLogsCommand) findAgentTag(dataDir string) (names.Tag, error) {
 	return nil, errors.New("no machine or controller agent configuration found")
 }
 
-func (c *dumpLogsCommand) dumpLogsForEnv(ctx *cmd.Context, statePool *state.StatePool, tag names.ModelTag) error {
+func (c *dumpLogsCommand) dumpLogsForEnv(ctx *cmd.Context, statePool *state.StatePool, tag names.ModelTag, stop <-chan struct{}) error {
 	st, err := statePool.Get(tag.Id())
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -191,30 +289,82 @@ func (c *dumpLogsCommand) dumpLogsForEnv(ctx *cmd.Context, statePool *state.Stat
 	writer := bufio.NewWriter(file)
 	defer writer.Flush()
 
-	tailer, err := state.NewLogTailer(st, corelogger.LogTailerParams{NoTail: true}, nil)
+	tailer, err := state.NewLogTailer(st, corelogger.LogTailerParams{
+		NoTail:        !c.follow,
+		StartTime:     c.startTime,
+		MinLevel:      c.minLevel,
+		IncludeModule: c.includeModule,
+		ExcludeModule: c.excludeModule,
+	}, nil)
 	if err != nil {
 		return errors.Annotate(err, "failed to create a log tailer")
 	}
+	defer tailer.Stop()
+
 	logs := tailer.Logs()
 	for {
-		rec, ok := <-logs
-		if !ok {
-			break
+		select {
+		case rec, ok := <-logs:
+			if !ok {
+				// The tailer stopped on its own, for example because the
+				// underlying log store was rotated or closed; treat that
+				// the same as reaching the end of the existing records.
+				return nil
+			}
+			if err := c.writeRecord(writer, rec); err != nil {
+				return errors.Annotate(err, "failed to write log record")
+			}
+			if c.follow {
+				// Flush straight away so a --follow reader tailing the
+				// output file sees each record as it arrives, rather than
+				// waiting for the buffer to fill or Run to return.
+				if err := writer.Flush(); err != nil {
+					return errors.Annotate(err, "failed to write log record")
+				}
+			}
+		case <-stop:
+			return nil
 		}
-		_, _ = writer.WriteString(c.format(
-			rec.Time,
-			rec.Level,
-			rec.Entity,
-			rec.Module,
-			rec.Message,
-			rec.Labels,
-		) + "\n")
 	}
+}
 
-	return nil
+// writeRecord writes a single log record to w, in the format selected by
+// --format. The json format writes newline-delimited objects so that the
+// output can be streamed and parsed line by line.
+func (c *dumpLogsCommand) writeRecord(w io.Writer, rec *corelogger.LogRecord) error {
+	switch c.format {
+	case "json":
+		data, err := json.Marshal(toLogRecord(rec))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "yaml":
+		data, err := goyaml.Marshal(toLogRecord(rec))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		_, err = fmt.Fprint(w, "---\n"+string(data))
+		return err
+	default:
+		_, err := fmt.Fprintln(w, formatText(rec))
+		return err
+	}
+}
+
+func toLogRecord(rec *corelogger.LogRecord) logRecord {
+	return logRecord{
+		Timestamp: rec.Time.In(time.UTC).Format("2006-01-02 15:04:05"),
+		Model:     rec.ModelUUID,
+		Entity:    rec.Entity,
+		Level:     rec.Level.String(),
+		Module:    rec.Module,
+		Message:   rec.Message,
+	}
 }
 
-func (c *dumpLogsCommand) format(timestamp time.Time, level loggo.Level, entity, module, message string, labels []string) string {
-	ts := timestamp.In(time.UTC).Format("2006-01-02 15:04:05")
-	return fmt.Sprintf("%s: %s %s %s %s %s", entity, ts, level, module, message, strings.Join(labels, ","))
+func formatText(rec *corelogger.LogRecord) string {
+	ts := rec.Time.In(time.UTC).Format("2006-01-02 15:04:05")
+	return fmt.Sprintf("%s: %s %s %s %s %s", rec.Entity, ts, rec.Level, rec.Module, rec.Message, strings.Join(rec.Labels, ","))
 }
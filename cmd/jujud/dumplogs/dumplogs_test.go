@@ -0,0 +1,43 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package dumplogs
+
+import (
+	stdtesting "testing"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+)
+
+func Test(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type ParseSinceSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&ParseSinceSuite{})
+
+func (s *ParseSinceSuite) TestParseSinceAbsolute(c *gc.C) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	got, err := parseSince("2023-06-01T10:00:00Z", now)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got.Equal(time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)), jc.IsTrue)
+}
+
+func (s *ParseSinceSuite) TestParseSinceRelative(c *gc.C) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	got, err := parseSince("30m", now)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got.Equal(now.Add(-30*time.Minute)), jc.IsTrue)
+}
+
+func (s *ParseSinceSuite) TestParseSinceInvalid(c *gc.C) {
+	_, err := parseSince("not a time", time.Now())
+	c.Assert(err, gc.ErrorMatches, `"not a time" is not an RFC3339 timestamp or a duration`)
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package introspect
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd/v3"
+	"github.com/juju/errors"
+
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/worker/uniter/hook"
+)
+
+// HookStateCommand prints the persisted hook execution state for a unit, to
+// help operators debug a uniter that appears stuck mid-hook. It prints the
+// kind, relation id, remote unit and application, storage id, departing
+// unit and workload name of the recorded hook.Info; its other fields, such
+// as Members, ChangeVersion, StartedAt and the secret fields, are not
+// printed. A hook "status" is not part of hook.Info and is not reported.
+type HookStateCommand struct {
+	cmd.CommandBase
+	statePath string
+}
+
+// NewHookStateCommand returns a command that prints the hook state
+// recorded in the state file at the given path.
+func NewHookStateCommand() cmd.Command {
+	return &HookStateCommand{}
+}
+
+// Info is part of cmd.Command.
+func (c *HookStateCommand) Info() *cmd.Info {
+	return jujucmd.Info(&cmd.Info{
+		Name:    "introspect-hook-state",
+		Args:    "<state-file-path>",
+		Purpose: "print the persisted hook execution state for a unit",
+	})
+}
+
+// Init is part of cmd.Command.
+func (c *HookStateCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("state-file-path argument is required")
+	}
+	c.statePath, args = args[0], args[1:]
+	return cmd.CheckEmpty(args)
+}
+
+// Run is part of cmd.Command.
+func (c *HookStateCommand) Run(ctx *cmd.Context) error {
+	info, err := hook.NewStateFile(c.statePath).Read()
+	if err == hook.ErrNoStateFile {
+		fmt.Fprintf(ctx.Stdout, "no hook state recorded at %s\n", c.statePath)
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+
+	fmt.Fprintf(ctx.Stdout, "kind: %s\n", info.Kind)
+	if info.RelationId != 0 || info.Kind.IsRelation() {
+		fmt.Fprintf(ctx.Stdout, "relation-id: %d\n", info.RelationId)
+	}
+	if info.RemoteUnit != "" {
+		fmt.Fprintf(ctx.Stdout, "remote-unit: %s\n", info.RemoteUnit)
+	}
+	if info.RemoteApplication != "" {
+		fmt.Fprintf(ctx.Stdout, "remote-application: %s\n", info.RemoteApplication)
+	}
+	if info.StorageId != "" {
+		fmt.Fprintf(ctx.Stdout, "storage-id: %s\n", info.StorageId)
+	}
+	if info.DepartingUnit != "" {
+		fmt.Fprintf(ctx.Stdout, "departing-unit: %s\n", info.DepartingUnit)
+	}
+	if info.WorkloadName != "" {
+		fmt.Fprintf(ctx.Stdout, "workload-name: %s\n", info.WorkloadName)
+	}
+	return nil
+}
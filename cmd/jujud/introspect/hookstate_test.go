@@ -0,0 +1,65 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package introspect_test
+
+import (
+	"path/filepath"
+
+	"github.com/juju/charm/v9/hooks"
+	"github.com/juju/cmd/v3/cmdtesting"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cmd/jujud/introspect"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/hook"
+)
+
+type HookStateCommandSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&HookStateCommandSuite{})
+
+func (s *HookStateCommandSuite) TestInitRequiresPath(c *gc.C) {
+	cmd := introspect.NewHookStateCommand()
+	err := cmd.Init(nil)
+	c.Assert(err, gc.ErrorMatches, "state-file-path argument is required")
+}
+
+func (s *HookStateCommandSuite) TestInitRejectsExtraArgs(c *gc.C) {
+	cmd := introspect.NewHookStateCommand()
+	err := cmd.Init([]string{"state", "extra"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+}
+
+func (s *HookStateCommandSuite) TestRunMissingStateFile(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "state")
+	cmd := introspect.NewHookStateCommand()
+	c.Assert(cmd.Init([]string{path}), jc.ErrorIsNil)
+	ctx := cmdtesting.Context(c)
+	c.Assert(cmd.Run(ctx), jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), jc.Contains, "no hook state recorded")
+}
+
+func (s *HookStateCommandSuite) TestRunRelationHookState(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "state")
+	info := &hook.Info{
+		Kind:              hooks.RelationJoined,
+		RelationId:        3,
+		RemoteUnit:        "mysql/0",
+		RemoteApplication: "mysql",
+	}
+	c.Assert(hook.NewStateFile(path).Write(info), jc.ErrorIsNil)
+
+	cmd := introspect.NewHookStateCommand()
+	c.Assert(cmd.Init([]string{path}), jc.ErrorIsNil)
+	ctx := cmdtesting.Context(c)
+	c.Assert(cmd.Run(ctx), jc.ErrorIsNil)
+	out := cmdtesting.Stdout(ctx)
+	c.Assert(out, jc.Contains, "kind: relation-joined")
+	c.Assert(out, jc.Contains, "relation-id: 3")
+	c.Assert(out, jc.Contains, "remote-unit: mysql/0")
+	c.Assert(out, jc.Contains, "remote-application: mysql")
+}
@@ -6,6 +6,7 @@ package main
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math/rand"
@@ -19,6 +20,7 @@ import (
 	"github.com/juju/cmd/v3"
 	"github.com/juju/errors"
 	"github.com/juju/featureflag"
+	"github.com/juju/gnuflag"
 	"github.com/juju/loggo"
 	"github.com/juju/names/v4"
 	proxyutils "github.com/juju/proxy"
@@ -241,6 +243,7 @@ func jujuDMain(args []string, ctx *cmd.Context) (code int, err error) {
 		Compiler:     jujuversion.Compiler,
 	}
 
+	logFlags := &logFormatFlags{}
 	jujud := jujucmd.NewSuperCommand(cmd.SuperCommandParams{
 		Name: "jujud",
 		Doc:  jujudDoc,
@@ -250,10 +253,11 @@ func jujuDMain(args []string, ctx *cmd.Context) (code int, err error) {
 		// tests to assert that this string value is correct.
 		Version:       detail.Version,
 		VersionDetail: detail,
+		GlobalFlags:   logFlags,
 	})
 
 	jujud.Log.NewWriter = func(target io.Writer) loggo.Writer {
-		return &jujudWriter{target: target}
+		return &jujudWriter{target: target, jsonFormat: logFlags.logFormat == logFormatJSON}
 	}
 
 	jujud.Register(agentcmd.NewBootstrapCommand())
@@ -283,6 +287,9 @@ func jujuDMain(args []string, ctx *cmd.Context) (code int, err error) {
 	jujud.Register(caasOperatorAgent)
 
 	jujud.Register(agentcmd.NewCheckConnectionCommand(agentConf, agentcmd.ConnectAsAgent))
+	jujud.Register(agentcmd.NewRotatePasswordCommand(agentConf, agentcmd.ConnectAsAgentAPI))
+	jujud.Register(agentcmd.NewCheckConfigCommand())
+	jujud.Register(introspect.NewHookStateCommand())
 
 	code = cmd.Main(jujud, ctx, args[1:])
 	return code, nil
@@ -302,9 +309,7 @@ func main() {
 func Main(args []string) int {
 	defer func() {
 		if r := recover(); r != nil {
-			buf := make([]byte, 4096)
-			buf = buf[:runtime.Stack(buf, false)]
-			logger.Criticalf("Unhandled panic: \n%v\n%s", r, buf)
+			logger.Criticalf("Unhandled panic: \n%v\n%s", r, panicStack())
 			os.Exit(exit_panic)
 		}
 	}()
@@ -346,11 +351,52 @@ func Main(args []string) int {
 	return code
 }
 
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// logFormatFlags adds the --log-format option to the jujud SuperCommand and
+// all of its subcommands, so that operators can request structured logging
+// (for shipping to Loki/ELK, for example) instead of the plain text default.
+type logFormatFlags struct {
+	logFormat string
+}
+
+// AddFlags implements cmd.FlagAdder.
+func (f *logFormatFlags) AddFlags(fs *gnuflag.FlagSet) {
+	fs.StringVar(&f.logFormat, "log-format", logFormatText, "specify the log format (text|json)")
+}
+
+// panicStack returns a formatted stack trace suitable for logging from the
+// top level panic handler. By default it captures only the panicking
+// goroutine; if JujuDebugFullStackEnvKey is set it captures every goroutine
+// instead, growing the buffer as needed, which is far more useful for
+// diagnosing deadlock-adjacent panics but can be large.
+func panicStack() []byte {
+	all := os.Getenv(osenv.JujuDebugFullStackEnvKey) != ""
+	if !all {
+		buf := make([]byte, 4096)
+		return buf[:runtime.Stack(buf, false)]
+	}
+	for size := 1 << 16; ; size *= 2 {
+		buf := make([]byte, size)
+		if n := runtime.Stack(buf, true); n < size {
+			return buf[:n]
+		}
+	}
+}
+
 type jujudWriter struct {
-	target io.Writer
+	target     io.Writer
+	jsonFormat bool
 }
 
 func (w *jujudWriter) Write(entry loggo.Entry) {
+	if w.jsonFormat {
+		fmt.Fprintln(w.target, w.jsonFormatEntry(entry))
+		return
+	}
 	if strings.HasPrefix(entry.Module, "unit.") {
 		fmt.Fprintln(w.target, w.unitFormat(entry))
 	} else {
@@ -360,8 +406,54 @@ func (w *jujudWriter) Write(entry loggo.Entry) {
 
 func (w *jujudWriter) unitFormat(entry loggo.Entry) string {
 	ts := entry.Timestamp.In(time.UTC).Format("2006-01-02 15:04:05")
+	unitName := unitNameFromModule(entry.Module)
 	// Just show the last element of the module.
 	lastDot := strings.LastIndex(entry.Module, ".")
 	module := entry.Module[lastDot+1:]
-	return fmt.Sprintf("%s %s %s %s", ts, entry.Level, module, entry.Message)
+	return fmt.Sprintf("%s %s %s %s %s", ts, entry.Level, unitName, module, entry.Message)
+}
+
+// unitNameFromModule extracts the unit name from a module of the form
+// "unit.<unit name>.<rest>", e.g. "unit.mysql/0.juju.worker.uniter" yields
+// "mysql/0". Callers are expected to only call this for modules with the
+// "unit." prefix.
+func unitNameFromModule(module string) string {
+	rest := strings.TrimPrefix(module, "unit.")
+	if dot := strings.Index(rest, "."); dot != -1 {
+		return rest[:dot]
+	}
+	return rest
+}
+
+// jsonLogEntry is the wire shape of a single jujudWriter log line when
+// --log-format=json is selected.
+type jsonLogEntry struct {
+	Timestamp string `json:"timestamp"`
+	Level     string `json:"level"`
+	Module    string `json:"module"`
+	Message   string `json:"message"`
+	Location  string `json:"location,omitempty"`
+	Unit      string `json:"unit,omitempty"`
+}
+
+// jsonFormatEntry renders entry as a single line of JSON, keeping the full
+// module name and recording the unit name separately when the entry was
+// logged by a unit worker.
+func (w *jujudWriter) jsonFormatEntry(entry loggo.Entry) string {
+	out := jsonLogEntry{
+		Timestamp: entry.Timestamp.In(time.UTC).Format("2006-01-02 15:04:05"),
+		Level:     entry.Level.String(),
+		Module:    entry.Module,
+		Message:   entry.Message,
+		Location:  fmt.Sprintf("%s:%d", entry.Filename, entry.Line),
+	}
+	if strings.HasPrefix(entry.Module, "unit.") {
+		out.Unit = unitNameFromModule(entry.Module)
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		// This should never happen: jsonLogEntry contains only strings.
+		return fmt.Sprintf(`{"level":"ERROR","message":%q}`, err.Error())
+	}
+	return string(data)
 }
@@ -5,6 +5,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -14,6 +15,7 @@ import (
 	"path/filepath"
 	"strings"
 	stdtesting "testing"
+	"time"
 
 	"github.com/juju/cmd/v3"
 	"github.com/juju/gnuflag"
@@ -24,6 +26,7 @@ import (
 	jujucmd "github.com/juju/juju/cmd"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/juju/names"
+	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/juju/sockets"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/worker/uniter/runner/jujuc"
@@ -91,6 +94,54 @@ var expectedProviders = []string{
 	"openstack",
 }
 
+// TestUnhandledPanicLogsAndExits calls the reentrant test binary with no
+// arguments at all, which makes the exported Main index into an empty args
+// slice, forcing a real panic that only the top level recover handler in
+// Main can catch.
+func TestUnhandledPanicLogsAndExits(t *stdtesting.T) {
+	args := []string{"-test.run", "TestRunMain", "-run-main", "--"}
+	ps := exec.Command(os.Args[0], args...)
+	output, err := ps.CombinedOutput()
+	if err == nil || err.Error() != "exit status 3" {
+		t.Fatalf("expected exit status 3, got %v (output: %s)", err, output)
+	}
+	if !strings.Contains(string(output), "Unhandled panic") {
+		t.Fatalf("expected output to mention the unhandled panic, got: %s", output)
+	}
+}
+
+func TestPanicStackDefaultIsSingleGoroutine(t *stdtesting.T) {
+	os.Unsetenv(osenv.JujuDebugFullStackEnvKey)
+	stack := panicStack()
+	if !strings.Contains(string(stack), "goroutine") {
+		t.Fatalf("expected stack to mention a goroutine, got: %s", stack)
+	}
+	if len(stack) > 4096 {
+		t.Fatalf("expected the default stack dump to be capped at 4096 bytes, got %d", len(stack))
+	}
+}
+
+func TestPanicStackFullCapturesAllGoroutines(t *stdtesting.T) {
+	if err := os.Setenv(osenv.JujuDebugFullStackEnvKey, "1"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv(osenv.JujuDebugFullStackEnvKey)
+
+	done := make(chan struct{})
+	parked := make(chan struct{})
+	go func() {
+		close(parked)
+		<-done
+	}()
+	defer close(done)
+	<-parked
+
+	stack := panicStack()
+	if n := strings.Count(string(stack), "goroutine "); n <= 1 {
+		t.Fatalf("expected the full stack dump to cover more than one goroutine, got %d", n)
+	}
+}
+
 func (s *MainSuite) TestProvidersAreRegistered(c *gc.C) {
 	// check that all the expected providers are registered
 	for _, name := range expectedProviders {
@@ -257,3 +308,105 @@ func (s *HookToolMainSuite) TestStdin(c *gc.C) {
 	output := runForTest(c, s.sockPath, "bill", 0, []byte("some standard input"), "remote")
 	c.Assert(output, gc.Equals, "some standard input")
 }
+
+type JujudWriterSuite struct{}
+
+var _ = gc.Suite(&JujudWriterSuite{})
+
+func (s *JujudWriterSuite) logEntry() loggo.Entry {
+	return loggo.Entry{
+		Level:     loggo.INFO,
+		Module:    "unit.mysql/0.juju.worker.uniter",
+		Filename:  "/path/to/uniter.go",
+		Line:      42,
+		Timestamp: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message:   "doing a thing",
+	}
+}
+
+func (s *JujudWriterSuite) TestWriteTextFormat(c *gc.C) {
+	var buf bytes.Buffer
+	w := &jujudWriter{target: &buf}
+	w.Write(s.logEntry())
+	c.Assert(buf.String(), gc.Equals, "2023-01-02 03:04:05 INFO mysql/0 uniter doing a thing\n")
+}
+
+func (s *JujudWriterSuite) TestWriteTextFormatNestedWorkerPath(c *gc.C) {
+	var buf bytes.Buffer
+	w := &jujudWriter{target: &buf}
+	entry := s.logEntry()
+	entry.Module = "unit.mysql/0.juju.worker.uniter.operation"
+	w.Write(entry)
+	c.Assert(buf.String(), gc.Equals, "2023-01-02 03:04:05 INFO mysql/0 operation doing a thing\n")
+}
+
+func (s *JujudWriterSuite) TestWriteTextFormatNoWorkerPath(c *gc.C) {
+	var buf bytes.Buffer
+	w := &jujudWriter{target: &buf}
+	entry := s.logEntry()
+	entry.Module = "unit.mysql/0"
+	w.Write(entry)
+	c.Assert(buf.String(), gc.Equals, "2023-01-02 03:04:05 INFO mysql/0 mysql/0 doing a thing\n")
+}
+
+func (s *JujudWriterSuite) TestWriteTextFormatNonUnit(c *gc.C) {
+	var buf bytes.Buffer
+	w := &jujudWriter{target: &buf}
+	entry := s.logEntry()
+	entry.Module = "juju.worker.uniter"
+	w.Write(entry)
+	c.Assert(buf.String(), gc.Equals, loggo.DefaultFormatter(entry)+"\n")
+}
+
+func (s *JujudWriterSuite) TestWriteJSONFormatUnit(c *gc.C) {
+	var buf bytes.Buffer
+	w := &jujudWriter{target: &buf, jsonFormat: true}
+	w.Write(s.logEntry())
+
+	var got map[string]interface{}
+	err := json.Unmarshal(buf.Bytes(), &got)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, map[string]interface{}{
+		"timestamp": "2023-01-02 03:04:05",
+		"level":     "INFO",
+		"module":    "unit.mysql/0.juju.worker.uniter",
+		"message":   "doing a thing",
+		"location":  "/path/to/uniter.go:42",
+		"unit":      "mysql/0",
+	})
+}
+
+func (s *JujudWriterSuite) TestWriteJSONFormatNonUnit(c *gc.C) {
+	var buf bytes.Buffer
+	w := &jujudWriter{target: &buf, jsonFormat: true}
+	entry := s.logEntry()
+	entry.Module = "juju.worker.uniter"
+	w.Write(entry)
+
+	var got map[string]interface{}
+	err := json.Unmarshal(buf.Bytes(), &got)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, map[string]interface{}{
+		"timestamp": "2023-01-02 03:04:05",
+		"level":     "INFO",
+		"module":    "juju.worker.uniter",
+		"message":   "doing a thing",
+		"location":  "/path/to/uniter.go:42",
+	})
+}
+
+func (s *JujudWriterSuite) TestLogFormatFlagsDefault(c *gc.C) {
+	f := &logFormatFlags{}
+	fs := gnuflag.NewFlagSet("jujud", gnuflag.ContinueOnError)
+	f.AddFlags(fs)
+	c.Assert(f.logFormat, gc.Equals, logFormatText)
+}
+
+func (s *JujudWriterSuite) TestLogFormatFlagsJSON(c *gc.C) {
+	f := &logFormatFlags{}
+	fs := gnuflag.NewFlagSet("jujud", gnuflag.ContinueOnError)
+	f.AddFlags(fs)
+	err := fs.Parse(false, []string{"--log-format", "json"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(f.logFormat, gc.Equals, logFormatJSON)
+}
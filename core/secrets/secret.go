@@ -120,6 +120,10 @@ type SecretMetadata struct {
 	// whenever an incompatible change is made.
 	Version int
 
+	// Ephemeral is true if the secret should be removed
+	// automatically when its owning unit is removed.
+	Ephemeral bool
+
 	// These can be updated after creation.
 	Description  string
 	Label        string
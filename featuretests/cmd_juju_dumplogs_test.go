@@ -5,6 +5,7 @@ package featuretests
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"time"
@@ -19,6 +20,7 @@ import (
 	"github.com/juju/juju/cmd/jujud/dumplogs"
 	corelogger "github.com/juju/juju/core/logger"
 	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/testing/factory"
 	"github.com/juju/juju/version"
 )
@@ -86,3 +88,271 @@ func (s *dumpLogsCommandSuite) TestRun(c *gc.C) {
 		c.Assert(scanner.Err(), jc.ErrorIsNil)
 	}
 }
+
+func (s *dumpLogsCommandSuite) TestRunJSONFormat(c *gc.C) {
+	// Create a controller machine and an agent for it.
+	m, password := s.Factory.MakeMachineReturningPassword(c, &factory.MachineParams{
+		Jobs:  []state.MachineJob{state.JobManageModel},
+		Nonce: agent.BootstrapNonce,
+	})
+	err := m.SetMongoPassword(password)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.PrimeStateAgent(c, m.Tag(), password)
+
+	// Populate a small log store for the controller model.
+	w := state.NewDbLogger(s.State)
+	defer w.Close()
+	t := time.Date(2015, 11, 4, 3, 2, 1, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		err := w.Log([]corelogger.LogRecord{{
+			Time:     t,
+			Entity:   "machine-42",
+			Version:  version.Current,
+			Module:   "module",
+			Location: "location",
+			Level:    loggo.INFO,
+			Message:  fmt.Sprintf("%d", i),
+			Labels:   []string{"http"},
+		}})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	// Run the juju-dumplogs command with --format json.
+	command := dumplogs.NewCommand()
+	context, err := cmdtesting.RunCommand(c, command, "--data-dir", s.DataDir(), "--format", "json")
+	c.Assert(err, jc.ErrorIsNil)
+
+	logName := context.AbsPath(fmt.Sprintf("%s.log", s.State.ModelUUID()))
+	logFile, err := os.Open(logName)
+	c.Assert(err, jc.ErrorIsNil)
+	defer logFile.Close()
+
+	type record struct {
+		Timestamp string `json:"timestamp"`
+		Model     string `json:"model"`
+		Entity    string `json:"entity"`
+		Level     string `json:"level"`
+		Module    string `json:"module"`
+		Message   string `json:"message"`
+	}
+
+	scanner := bufio.NewScanner(logFile)
+	for i := 0; scanner.Scan(); i++ {
+		var rec record
+		err := json.Unmarshal(scanner.Bytes(), &rec)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(rec, gc.Equals, record{
+			Timestamp: "2015-11-04 03:02:01",
+			Model:     s.State.ModelUUID(),
+			Entity:    "machine-42",
+			Level:     "INFO",
+			Module:    "module",
+			Message:   fmt.Sprintf("%d", i),
+		})
+	}
+	c.Assert(scanner.Err(), jc.ErrorIsNil)
+}
+
+func (s *dumpLogsCommandSuite) TestRunFilters(c *gc.C) {
+	// Create a controller machine and an agent for it.
+	m, password := s.Factory.MakeMachineReturningPassword(c, &factory.MachineParams{
+		Jobs:  []state.MachineJob{state.JobManageModel},
+		Nonce: agent.BootstrapNonce,
+	})
+	err := m.SetMongoPassword(password)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.PrimeStateAgent(c, m.Tag(), password)
+
+	w := state.NewDbLogger(s.State)
+	defer w.Close()
+	t := time.Date(2015, 11, 4, 3, 2, 1, 0, time.UTC)
+	records := []corelogger.LogRecord{
+		{Time: t, Entity: "machine-42", Version: version.Current, Module: "juju.worker.uniter", Level: loggo.INFO, Message: "uniter info"},
+		{Time: t, Entity: "machine-42", Version: version.Current, Module: "juju.worker.uniter", Level: loggo.ERROR, Message: "uniter error"},
+		{Time: t, Entity: "machine-42", Version: version.Current, Module: "juju.worker.provisioner", Level: loggo.ERROR, Message: "provisioner error"},
+	}
+	for _, rec := range records {
+		err := w.Log([]corelogger.LogRecord{rec})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	command := dumplogs.NewCommand()
+	context, err := cmdtesting.RunCommand(c, command,
+		"--data-dir", s.DataDir(),
+		"--level", "ERROR",
+		"--include-module", "juju.worker.uniter",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	logName := context.AbsPath(fmt.Sprintf("%s.log", s.State.ModelUUID()))
+	logFile, err := os.Open(logName)
+	c.Assert(err, jc.ErrorIsNil)
+	defer logFile.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(logFile)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	c.Assert(scanner.Err(), jc.ErrorIsNil)
+
+	// Only the uniter ERROR message satisfies both the level threshold
+	// and the module filter.
+	c.Assert(lines, gc.HasLen, 1)
+	c.Assert(lines[0], gc.Equals, "machine-42: 2015-11-04 03:02:01 ERROR juju.worker.uniter uniter error ")
+}
+
+func (s *dumpLogsCommandSuite) TestRunSinceFilter(c *gc.C) {
+	// Create a controller machine and an agent for it.
+	m, password := s.Factory.MakeMachineReturningPassword(c, &factory.MachineParams{
+		Jobs:  []state.MachineJob{state.JobManageModel},
+		Nonce: agent.BootstrapNonce,
+	})
+	err := m.SetMongoPassword(password)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.PrimeStateAgent(c, m.Tag(), password)
+
+	w := state.NewDbLogger(s.State)
+	defer w.Close()
+	older := time.Date(2015, 11, 4, 3, 0, 0, 0, time.UTC)
+	boundary := time.Date(2015, 11, 4, 3, 2, 1, 0, time.UTC)
+	records := []corelogger.LogRecord{
+		{Time: older, Entity: "machine-42", Version: version.Current, Module: "module", Level: loggo.INFO, Message: "too old"},
+		{Time: boundary, Entity: "machine-42", Version: version.Current, Module: "module", Level: loggo.INFO, Message: "on boundary"},
+	}
+	for _, rec := range records {
+		err := w.Log([]corelogger.LogRecord{rec})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	command := dumplogs.NewCommand()
+	context, err := cmdtesting.RunCommand(c, command,
+		"--data-dir", s.DataDir(),
+		"--since", boundary.Format(time.RFC3339),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	logName := context.AbsPath(fmt.Sprintf("%s.log", s.State.ModelUUID()))
+	logFile, err := os.Open(logName)
+	c.Assert(err, jc.ErrorIsNil)
+	defer logFile.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(logFile)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	c.Assert(scanner.Err(), jc.ErrorIsNil)
+
+	// The record exactly at the --since boundary is included; the older
+	// one is not.
+	c.Assert(lines, gc.HasLen, 1)
+	c.Assert(lines[0], gc.Equals, "machine-42: 2015-11-04 03:02:01 INFO module on boundary ")
+}
+
+func (s *dumpLogsCommandSuite) TestRunSinceRelativeAndOutOfRange(c *gc.C) {
+	// Create a controller machine and an agent for it.
+	m, password := s.Factory.MakeMachineReturningPassword(c, &factory.MachineParams{
+		Jobs:  []state.MachineJob{state.JobManageModel},
+		Nonce: agent.BootstrapNonce,
+	})
+	err := m.SetMongoPassword(password)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.PrimeStateAgent(c, m.Tag(), password)
+
+	w := state.NewDbLogger(s.State)
+	defer w.Close()
+	err = w.Log([]corelogger.LogRecord{{
+		Time: time.Now().UTC(), Entity: "machine-42", Version: version.Current,
+		Module: "module", Level: loggo.INFO, Message: "recent",
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// A relative --since well inside the record's age should include it.
+	command := dumplogs.NewCommand()
+	context, err := cmdtesting.RunCommand(c, command, "--data-dir", s.DataDir(), "--since", "1h")
+	c.Assert(err, jc.ErrorIsNil)
+	logName := context.AbsPath(fmt.Sprintf("%s.log", s.State.ModelUUID()))
+	lines, err := os.ReadFile(logName)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(len(lines) > 0, jc.IsTrue)
+
+	// An absolute --since in the future excludes everything.
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	command = dumplogs.NewCommand()
+	context, err = cmdtesting.RunCommand(c, command, "--data-dir", s.DataDir(), "--since", future)
+	c.Assert(err, jc.ErrorIsNil)
+	logName = context.AbsPath(fmt.Sprintf("%s.log", s.State.ModelUUID()))
+	lines, err = os.ReadFile(logName)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lines, gc.HasLen, 0)
+}
+
+func (s *dumpLogsCommandSuite) TestRunFollow(c *gc.C) {
+	// Create a controller machine and an agent for it.
+	m, password := s.Factory.MakeMachineReturningPassword(c, &factory.MachineParams{
+		Jobs:  []state.MachineJob{state.JobManageModel},
+		Nonce: agent.BootstrapNonce,
+	})
+	err := m.SetMongoPassword(password)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.PrimeStateAgent(c, m.Tag(), password)
+
+	w := state.NewDbLogger(s.State)
+	defer w.Close()
+	t := time.Date(2015, 11, 4, 3, 2, 1, 0, time.UTC)
+	logRecord := func(msg string) corelogger.LogRecord {
+		return corelogger.LogRecord{
+			Time: t, Entity: "machine-42", Version: version.Current,
+			Module: "module", Location: "location", Level: loggo.INFO, Message: msg,
+		}
+	}
+
+	err = w.Log([]corelogger.LogRecord{logRecord("0")})
+	c.Assert(err, jc.ErrorIsNil)
+
+	command := dumplogs.NewCommand()
+	err = cmdtesting.InitCommand(command, []string{"--data-dir", s.DataDir(), "--follow"})
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := cmdtesting.Context(c)
+
+	done := make(chan error, 1)
+	go func() { done <- command.Run(ctx) }()
+
+	logName := ctx.AbsPath(fmt.Sprintf("%s.log", s.State.ModelUUID()))
+	s.waitForDumpLogLines(c, logName, 1)
+
+	err = w.Log([]corelogger.LogRecord{logRecord("1")})
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForDumpLogLines(c, logName, 2)
+
+	select {
+	case err := <-done:
+		c.Fatalf("follow stopped unexpectedly: %v", err)
+	default:
+	}
+}
+
+func (s *dumpLogsCommandSuite) waitForDumpLogLines(c *gc.C, logName string, want int) {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		logFile, err := os.Open(logName)
+		if err != nil {
+			continue
+		}
+		var got int
+		scanner := bufio.NewScanner(logFile)
+		for scanner.Scan() {
+			got++
+		}
+		logFile.Close()
+		if got >= want {
+			return
+		}
+	}
+	c.Fatalf("timed out waiting for %d lines in %s", want, logName)
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osenv
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// WriteEnvFile writes env to path as a sequence of "KEY=VALUE" lines, one
+// per entry, for a launched process that reads its environment from a
+// file rather than inheriting it directly. Each value is quoted with
+// strconv.Quote so that values containing spaces, equals signs, newlines
+// or other special characters round-trip exactly through ReadEnvFile.
+func WriteEnvFile(path string, env map[string]string) error {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, k := range keys {
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		buf.WriteString(strconv.Quote(env[k]))
+		buf.WriteByte('\n')
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// ReadEnvFile reads an environment map written by WriteEnvFile.
+func ReadEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		key, quoted, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid env file line %q: missing %q", line, "=")
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid env file line %q", line)
+		}
+		env[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return env, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osenv_test
+
+import (
+	"os"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/testing"
+)
+
+type envFileSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&envFileSuite{})
+
+func (s *envFileSuite) TestRoundTrip(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "env")
+	env := map[string]string{
+		"PATH":           "/usr/bin:/bin",
+		"WITH_SPACES":    "hello world",
+		"WITH_EQUALS":    "a=b=c",
+		"WITH_NEWLINE":   "line one\nline two",
+		"EMPTY":          "",
+		"WITH_QUOTES":    `he said "hi"`,
+		"WITH_BACKSLASH": `C:\some\path`,
+	}
+
+	err := osenv.WriteEnvFile(path, env)
+	c.Assert(err, jc.ErrorIsNil)
+
+	got, err := osenv.ReadEnvFile(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, env)
+}
+
+func (s *envFileSuite) TestReadEnvFileMissingEquals(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "env")
+	err := os.WriteFile(path, []byte("NOVALUE\n"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = osenv.ReadEnvFile(path)
+	c.Assert(err, gc.ErrorMatches, `invalid env file line "NOVALUE": missing "="`)
+}
+
+func (s *envFileSuite) TestWriteEnvFileEmptyMap(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "env")
+	err := osenv.WriteEnvFile(path, map[string]string{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	got, err := osenv.ReadEnvFile(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, gc.HasLen, 0)
+}
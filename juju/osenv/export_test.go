@@ -8,4 +8,6 @@ var (
 	JujuXDGDataHomeLinux = jujuXDGDataHomeLinux
 	MergeEnvUnix         = mergeEnvUnix
 	MergeEnvWin          = mergeEnvWin
+	LogDirUnix           = logDirUnix
+	LogDirWin            = logDirWin
 )
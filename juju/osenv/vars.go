@@ -4,10 +4,14 @@
 package osenv
 
 import (
+	"os"
 	"runtime"
+	"strconv"
 	"strings"
 
+	"github.com/juju/collections/set"
 	"github.com/juju/featureflag"
+	"github.com/juju/proxy"
 )
 
 const (
@@ -58,11 +62,67 @@ const (
 	// process for the destroying and removal commands.
 	JujuSkipConfirmationEnvKey = "JUJU_SKIP_CONFIRMATION"
 
+	// JujuDebugFullStackEnvKey if set causes jujud's top level panic
+	// handler to dump the stacks of all goroutines, not just the one that
+	// panicked, to aid debugging deadlock-adjacent panics.
+	JujuDebugFullStackEnvKey = "JUJU_DEBUG_FULL_STACK"
+
+	// JujudAgentPasswordEnvKey if set is used in preference to the
+	// Windows registry (see JujuRegistryPasswordKey) as the source of the
+	// jujud agent's password. This lets containerized Windows agents and
+	// test harnesses, which can't rely on the registry, supply the
+	// password directly.
+	JujudAgentPasswordEnvKey = "JUJUD_AGENT_PASSWORD"
+
 	// XDGDataHome is a path where data for the running user
 	// should be stored according to the xdg standard.
 	XDGDataHome = "XDG_DATA_HOME"
+
+	// JujuLogDirEnvKey if set overrides the platform default directory
+	// returned by LogDir.
+	JujuLogDirEnvKey = "JUJU_LOG_DIR"
 )
 
+// jujuEnvKeys lists every Juju*EnvKey constant defined above, for use by
+// UnsetAllJujuEnv. Keep this in sync when adding a new one.
+var jujuEnvKeys = []string{
+	JujuControllerEnvKey,
+	JujuModelEnvKey,
+	JujuXDGDataHomeEnvKey,
+	JujuLoggingConfigEnvKey,
+	JujuFeatureFlagEnvKey,
+	JujuStartupLoggingConfigEnvKey,
+	JujuContainerTypeEnvKey,
+	JujuStatusIsoTimeEnvKey,
+	JujuSkipConfirmationEnvKey,
+	JujuDebugFullStackEnvKey,
+	JujudAgentPasswordEnvKey,
+	JujuLogDirEnvKey,
+}
+
+// UnsetAllJujuEnv unsets every Juju*EnvKey environment variable and returns
+// a restore function that reinstates whatever values were previously set.
+// It is intended to give tests cheap, complete isolation from the caller's
+// environment without having to enumerate and restore each variable by
+// hand.
+func UnsetAllJujuEnv() (restore func()) {
+	old := make(map[string]string, len(jujuEnvKeys))
+	oldOk := make(map[string]bool, len(jujuEnvKeys))
+	for _, key := range jujuEnvKeys {
+		old[key], oldOk[key] = os.LookupEnv(key)
+		os.Unsetenv(key)
+	}
+	return func() {
+		for _, key := range jujuEnvKeys {
+			if oldOk[key] {
+				os.Setenv(key, old[key])
+			} else {
+				os.Unsetenv(key)
+			}
+		}
+	}
+}
+
 // FeatureFlags returns a map that can be merged with os.Environ.
 func FeatureFlags() map[string]string {
 	result := make(map[string]string)
@@ -72,9 +132,38 @@ func FeatureFlags() map[string]string {
 	return result
 }
 
+// SetFeatureFlags sets JujuFeatureFlagEnvKey to the comma-separated join of
+// flags, replacing whatever was previously set. Callers that want to add to
+// the existing flags instead should use MergeFeatureFlags.
+func SetFeatureFlags(flags ...string) {
+	os.Setenv(JujuFeatureFlagEnvKey, strings.Join(flags, ","))
+}
+
+// MergeFeatureFlags adds flags to whatever is already set in
+// JujuFeatureFlagEnvKey, de-duplicating the result. Order is not
+// significant, since featureflag.Enabled treats the set as unordered.
+func MergeFeatureFlags(flags ...string) {
+	existing := set.NewStrings(strings.Split(os.Getenv(JujuFeatureFlagEnvKey), ",")...)
+	existing.Remove("")
+	for _, flag := range flags {
+		existing.Add(flag)
+	}
+	os.Setenv(JujuFeatureFlagEnvKey, strings.Join(existing.SortedValues(), ","))
+}
+
+// FeatureFlagEnabled reports whether flag is present in the set of feature
+// flags most recently loaded from JujuFeatureFlagEnvKey, consulting the
+// same featureflag source that FeatureFlags uses. It lets command code
+// gate experimental behaviour without importing the featureflag package
+// directly.
+func FeatureFlagEnabled(flag string) bool {
+	return featureflag.Enabled(flag)
+}
+
 // MergeEnvironment will return the current environment updated with
 // all the values from newValues.  If current is nil, a new map is
-// created.  If current is not nil, it is mutated.
+// created.  If current is not nil, it is mutated: callers that need the
+// original map left untouched should use MergeEnvironmentCopy instead.
 func MergeEnvironment(current, newValues map[string]string) map[string]string {
 	if current == nil {
 		current = make(map[string]string)
@@ -85,6 +174,113 @@ func MergeEnvironment(current, newValues map[string]string) map[string]string {
 	return mergeEnvUnix(current, newValues)
 }
 
+// MergeEnvironmentCopy behaves like MergeEnvironment but never mutates
+// current: it always merges into a freshly allocated map, leaving both
+// arguments untouched.
+func MergeEnvironmentCopy(current, newValues map[string]string) map[string]string {
+	fresh := make(map[string]string, len(current))
+	for k, v := range current {
+		fresh[k] = v
+	}
+	return MergeEnvironment(fresh, newValues)
+}
+
+// JujuData returns the value of the JUJU_DATA environment variable, or, if
+// it is unset, the platform default juju client configuration directory.
+func JujuData() string {
+	if dir := os.Getenv(JujuXDGDataHomeEnvKey); dir != "" {
+		return dir
+	}
+	if runtime.GOOS == "windows" {
+		return jujuXDGDataHomeWin()
+	}
+	return jujuXDGDataHomeLinux()
+}
+
+// LogDir returns the value of JUJU_LOG_DIR if set, or else the platform
+// default directory where juju agents write their logs.
+func LogDir() string {
+	if dir := os.Getenv(JujuLogDirEnvKey); dir != "" {
+		return dir
+	}
+	if runtime.GOOS == "windows" {
+		return logDirWin()
+	}
+	return logDirUnix()
+}
+
+// validContainerTypes are the values JujuContainerTypeEnvKey is expected
+// to take; they mirror the known container types in core/instance, which
+// osenv does not import to avoid a dependency on higher-level packages.
+var validContainerTypes = set.NewStrings("lxd", "kvm", "none")
+
+// ContainerType returns the value of JujuContainerTypeEnvKey and whether
+// it was set to one of the known container types. Code paths that behave
+// differently inside a container should use this instead of reading the
+// raw environment variable, so that an unset or unrecognised value is
+// handled consistently.
+func ContainerType() (string, bool) {
+	containerType := os.Getenv(JujuContainerTypeEnvKey)
+	if !validContainerTypes.Contains(containerType) {
+		return "", false
+	}
+	return containerType, true
+}
+
+// JujudAgentPassword returns the jujud agent's password, preferring
+// JujudAgentPasswordEnvKey when it is set. Otherwise it falls back to
+// calling registryLookup, which callers on Windows should wire up to read
+// JujuRegistryPasswordKey from the registry.
+func JujudAgentPassword(registryLookup func() (string, error)) (string, error) {
+	if password := os.Getenv(JujudAgentPasswordEnvKey); password != "" {
+		return password, nil
+	}
+	return registryLookup()
+}
+
+// StatusIsoTime reports whether JujuStatusIsoTimeEnvKey is set to a truthy
+// value, defaulting to false if it is unset or cannot be parsed as a bool.
+func StatusIsoTime() bool {
+	isoTime, err := strconv.ParseBool(os.Getenv(JujuStatusIsoTimeEnvKey))
+	if err != nil {
+		return false
+	}
+	return isoTime
+}
+
+// ProxyConfigFromEnv returns the proxy settings found in the environment,
+// reading both the lowercase and uppercase forms of each variable and
+// preferring the uppercase value when both are set.
+func ProxyConfigFromEnv() proxy.Settings {
+	return proxy.Settings{
+		Http:    proxyEnvSetting("http_proxy"),
+		Https:   proxyEnvSetting("https_proxy"),
+		Ftp:     proxyEnvSetting("ftp_proxy"),
+		NoProxy: proxyEnvSetting("no_proxy"),
+	}
+}
+
+// proxyEnvSetting returns the value of the uppercase form of key if set,
+// falling back to the lowercase form.
+func proxyEnvSetting(key string) string {
+	if value := os.Getenv(strings.ToUpper(key)); value != "" {
+		return value
+	}
+	return os.Getenv(key)
+}
+
+// logDirUnix returns the directory where juju agents write their logs on
+// Unix-like systems.
+func logDirUnix() string {
+	return "/var/log/juju"
+}
+
+// logDirWin returns the directory where juju agents write their logs on
+// Windows, alongside the rest of juju's registry-configured install.
+func logDirWin() string {
+	return "C:/Juju/log"
+}
+
 // mergeEnvUnix merges the two evironment variable lists in a case sensitive way.
 func mergeEnvUnix(current, newValues map[string]string) map[string]string {
 	for key, value := range newValues {
@@ -48,3 +48,7 @@ func (s *varsSuite) TestJujuXDGDataHomeNoXDGDefaultsConfig(c *gc.C) {
 	homeLinux := osenv.JujuXDGDataHomeLinux()
 	c.Assert(homeLinux, gc.Equals, "/a/bogus/user/home/.local/share/juju")
 }
+
+func (s *varsSuite) TestLogDirUnix(c *gc.C) {
+	c.Assert(osenv.LogDirUnix(), gc.Equals, "/var/log/juju")
+}
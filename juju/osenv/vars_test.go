@@ -5,8 +5,11 @@
 package osenv_test
 
 import (
+	"os"
 	"runtime"
 
+	"github.com/juju/errors"
+	"github.com/juju/featureflag"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -43,6 +46,37 @@ func (s *varsSuite) TestBlankJujuXDGDataHomeEnvVar(c *gc.C) {
 	}
 }
 
+func (s *varsSuite) TestJujuDataEnvVar(c *gc.C) {
+	path := "/foo/bar/baz"
+	s.PatchEnvironment(osenv.JujuXDGDataHomeEnvKey, path)
+	c.Assert(osenv.JujuData(), gc.Equals, path)
+}
+
+func (s *varsSuite) TestJujuDataUnsetFallsBackToPlatformDefault(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuXDGDataHomeEnvKey, "")
+
+	if runtime.GOOS == "windows" {
+		s.PatchEnvironment("APPDATA", `P:\foobar`)
+	} else {
+		s.PatchEnvironment("HOME", "/foobar")
+	}
+	c.Assert(osenv.JujuData(), gc.Not(gc.Equals), "")
+
+	if runtime.GOOS == "windows" {
+		c.Assert(osenv.JujuData(), gc.Equals, osenv.JujuXDGDataHomeWin())
+	} else {
+		c.Assert(osenv.JujuData(), gc.Equals, osenv.JujuXDGDataHomeLinux())
+	}
+}
+
+func (s *varsSuite) TestFeatureFlagEnabled(c *gc.C) {
+	s.SetFeatureFlags("foo", "BAR")
+	c.Assert(osenv.FeatureFlagEnabled("foo"), jc.IsTrue)
+	c.Assert(osenv.FeatureFlagEnabled("bar"), jc.IsTrue)
+	c.Assert(osenv.FeatureFlagEnabled("BAR"), jc.IsTrue)
+	c.Assert(osenv.FeatureFlagEnabled("baz"), jc.IsFalse)
+}
+
 func (s *varsSuite) TestMergeEnvironment(c *gc.C) {
 	c.Check(osenv.MergeEnvironment(nil, nil), gc.HasLen, 0)
 	newValues := map[string]string{"a": "baz", "c": "omg"}
@@ -54,6 +88,112 @@ func (s *varsSuite) TestMergeEnvironment(c *gc.C) {
 	c.Check(created, jc.DeepEquals, expected)
 }
 
+func (s *varsSuite) TestLogDirEnvVar(c *gc.C) {
+	path := "/custom/log/dir"
+	s.PatchEnvironment(osenv.JujuLogDirEnvKey, path)
+	c.Assert(osenv.LogDir(), gc.Equals, path)
+}
+
+func (s *varsSuite) TestLogDirDefault(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuLogDirEnvKey, "")
+	if runtime.GOOS == "windows" {
+		c.Assert(osenv.LogDir(), gc.Equals, osenv.LogDirWin())
+	} else {
+		c.Assert(osenv.LogDir(), gc.Equals, osenv.LogDirUnix())
+	}
+}
+
+func (s *varsSuite) TestContainerTypeSetValid(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuContainerTypeEnvKey, "lxd")
+	containerType, ok := osenv.ContainerType()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(containerType, gc.Equals, "lxd")
+}
+
+func (s *varsSuite) TestContainerTypeSetInvalid(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuContainerTypeEnvKey, "docker")
+	containerType, ok := osenv.ContainerType()
+	c.Assert(ok, jc.IsFalse)
+	c.Assert(containerType, gc.Equals, "")
+}
+
+func (s *varsSuite) TestContainerTypeUnset(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuContainerTypeEnvKey, "")
+	containerType, ok := osenv.ContainerType()
+	c.Assert(ok, jc.IsFalse)
+	c.Assert(containerType, gc.Equals, "")
+}
+
+func (s *varsSuite) TestProxyConfigFromEnvPrefersUppercase(c *gc.C) {
+	s.PatchEnvironment("http_proxy", "http://lower")
+	s.PatchEnvironment("HTTP_PROXY", "http://upper")
+	s.PatchEnvironment("https_proxy", "https://lower")
+	s.PatchEnvironment("HTTPS_PROXY", "")
+	s.PatchEnvironment("ftp_proxy", "ftp://lower")
+	s.PatchEnvironment("no_proxy", "localhost")
+
+	settings := osenv.ProxyConfigFromEnv()
+	c.Check(settings.Http, gc.Equals, "http://upper")
+	c.Check(settings.Https, gc.Equals, "https://lower")
+	c.Check(settings.Ftp, gc.Equals, "ftp://lower")
+	c.Check(settings.NoProxy, gc.Equals, "localhost")
+}
+
+func (s *varsSuite) TestStatusIsoTime(c *gc.C) {
+	for _, t := range []struct {
+		value    string
+		expected bool
+	}{
+		{"true", true},
+		{"1", true},
+		{"", false},
+		{"garbage", false},
+	} {
+		s.PatchEnvironment(osenv.JujuStatusIsoTimeEnvKey, t.value)
+		c.Check(osenv.StatusIsoTime(), gc.Equals, t.expected, gc.Commentf("value %q", t.value))
+	}
+}
+
+func (s *varsSuite) TestJujudAgentPasswordEnvSet(c *gc.C) {
+	s.PatchEnvironment(osenv.JujudAgentPasswordEnvKey, "env-password")
+	registryLookup := func() (string, error) {
+		c.Fatal("registryLookup should not be called when the env var is set")
+		return "", nil
+	}
+	password, err := osenv.JujudAgentPassword(registryLookup)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(password, gc.Equals, "env-password")
+}
+
+func (s *varsSuite) TestJujudAgentPasswordEnvUnsetRegistryPresent(c *gc.C) {
+	s.PatchEnvironment(osenv.JujudAgentPasswordEnvKey, "")
+	registryLookup := func() (string, error) {
+		return "registry-password", nil
+	}
+	password, err := osenv.JujudAgentPassword(registryLookup)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(password, gc.Equals, "registry-password")
+}
+
+func (s *varsSuite) TestJujudAgentPasswordBothAbsent(c *gc.C) {
+	s.PatchEnvironment(osenv.JujudAgentPasswordEnvKey, "")
+	registryLookup := func() (string, error) {
+		return "", errors.NotFoundf("registry value")
+	}
+	_, err := osenv.JujudAgentPassword(registryLookup)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *varsSuite) TestMergeEnvironmentCopyLeavesBaseUnchanged(c *gc.C) {
+	base := map[string]string{"a": "foo", "b": "bar"}
+	newValues := map[string]string{"a": "baz", "c": "omg"}
+
+	merged := osenv.MergeEnvironmentCopy(base, newValues)
+
+	c.Check(merged, jc.DeepEquals, map[string]string{"a": "baz", "b": "bar", "c": "omg"})
+	c.Check(base, jc.DeepEquals, map[string]string{"a": "foo", "b": "bar"})
+}
+
 func (s *varsSuite) TestMergeEnvWin(c *gc.C) {
 	initial := map[string]string{"a": "foo", "b": "bar", "foo": "val"}
 	newValues := map[string]string{"a": "baz", "c": "omg", "FOO": "val2", "d": "another"}
@@ -65,6 +205,52 @@ func (s *varsSuite) TestMergeEnvWin(c *gc.C) {
 	c.Check(initial, jc.DeepEquals, expected)
 }
 
+func (s *varsSuite) TestSetFeatureFlagsRoundTrips(c *gc.C) {
+	osenv.SetFeatureFlags("foo", "bar")
+	c.Assert(os.Getenv(osenv.JujuFeatureFlagEnvKey), gc.Equals, "foo,bar")
+
+	featureflag.SetFlagsFromEnvironment(osenv.JujuFeatureFlagEnvKey)
+	c.Assert(featureflag.All(), jc.SameContents, []string{"foo", "bar"})
+}
+
+func (s *varsSuite) TestSetFeatureFlagsReplacesExisting(c *gc.C) {
+	osenv.SetFeatureFlags("foo")
+	osenv.SetFeatureFlags("bar")
+	c.Assert(os.Getenv(osenv.JujuFeatureFlagEnvKey), gc.Equals, "bar")
+}
+
+func (s *varsSuite) TestMergeFeatureFlagsDedupes(c *gc.C) {
+	osenv.SetFeatureFlags("foo", "bar")
+	osenv.MergeFeatureFlags("bar", "baz")
+
+	featureflag.SetFlagsFromEnvironment(osenv.JujuFeatureFlagEnvKey)
+	c.Assert(featureflag.All(), jc.SameContents, []string{"foo", "bar", "baz"})
+}
+
+func (s *varsSuite) TestMergeFeatureFlagsWithNoneSet(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuFeatureFlagEnvKey, "")
+	osenv.MergeFeatureFlags("foo")
+	c.Assert(os.Getenv(osenv.JujuFeatureFlagEnvKey), gc.Equals, "foo")
+}
+
+func (s *varsSuite) TestUnsetAllJujuEnv(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuModelEnvKey, "my-model")
+	s.PatchEnvironment(osenv.JujuControllerEnvKey, "my-controller")
+	s.PatchEnvironment(osenv.JujuLoggingConfigEnvKey, "DEBUG")
+
+	restore := osenv.UnsetAllJujuEnv()
+
+	c.Check(os.Getenv(osenv.JujuModelEnvKey), gc.Equals, "")
+	c.Check(os.Getenv(osenv.JujuControllerEnvKey), gc.Equals, "")
+	c.Check(os.Getenv(osenv.JujuLoggingConfigEnvKey), gc.Equals, "")
+
+	restore()
+
+	c.Check(os.Getenv(osenv.JujuModelEnvKey), gc.Equals, "my-model")
+	c.Check(os.Getenv(osenv.JujuControllerEnvKey), gc.Equals, "my-controller")
+	c.Check(os.Getenv(osenv.JujuLoggingConfigEnvKey), gc.Equals, "DEBUG")
+}
+
 func (s *varsSuite) TestMergeEnvUnix(c *gc.C) {
 	initial := map[string]string{"a": "foo", "b": "bar"}
 	newValues := map[string]string{"a": "baz", "c": "omg", "d": "another"}
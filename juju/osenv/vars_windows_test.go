@@ -17,3 +17,7 @@ func (s *varsSuite) TestJujuXDGDataHome(c *gc.C) {
 	s.PatchEnvironment("APPDATA", path)
 	c.Assert(osenv.JujuXDGDataHomeWin(), gc.Equals, filepath.Join(path, "Juju"))
 }
+
+func (s *varsSuite) TestLogDirWin(c *gc.C) {
+	c.Assert(osenv.LogDirWin(), gc.Equals, "C:/Juju/log")
+}
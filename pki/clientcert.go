@@ -0,0 +1,81 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package pki
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// NewClientCert generates a new client certificate/key pair for use in
+// mutual TLS, signed by the supplied CA certificate and key. The resulting
+// certificate carries commonName as its subject and is flagged with
+// ExtKeyUsageClientAuth so that servers can distinguish it from a server
+// leaf certificate during verification.
+func NewClientCert(commonName string, caCertPEM, caKeyPEM []byte, expiry time.Time) (certPEM, keyPEM []byte, err error) {
+	caCert, caSigner, err := unmarshalCAPemData(caCertPEM, caKeyPEM)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+
+	signer, err := DefaultKeyProfile()
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName, Organization: Organisation},
+		NotBefore:   time.Now().Add(NotBeforeJitter),
+		NotAfter:    expiry,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if err := assetTagCertificate(template); err != nil {
+		return nil, nil, errors.Annotate(err, "failed tagging new client certificate")
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, signer.Public(), caSigner)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "failed creating client certificate")
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+
+	certStr, err := CertificateToPemString(DefaultPemHeaders, cert)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	keyStr, err := SignerToPemString(signer)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return []byte(certStr), []byte(keyStr), nil
+}
+
+// unmarshalCAPemData parses a CA certificate and private key from PEM data,
+// rejecting a signer that isn't a valid certificate authority.
+func unmarshalCAPemData(caCertPEM, caKeyPEM []byte) (*x509.Certificate, crypto.Signer, error) {
+	certs, signers, err := UnmarshalPemData(append(append([]byte{}, caCertPEM...), caKeyPEM...))
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if len(certs) == 0 {
+		return nil, nil, errors.NotValidf("no CA certificate found")
+	}
+	if len(signers) != 1 {
+		return nil, nil, errors.NotValidf("expected exactly one CA private key, found %d", len(signers))
+	}
+	caCert := certs[0]
+	if !caCert.IsCA {
+		return nil, nil, errors.NotValidf("%s is not a certificate authority", caCert.Subject)
+	}
+	return caCert, signers[0], nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package pki_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/pki"
+	pkitest "github.com/juju/juju/pki/test"
+)
+
+type ClientCertSuite struct{}
+
+var _ = gc.Suite(&ClientCertSuite{})
+
+func (s *ClientCertSuite) TestNewClientCertMutualTLSHandshake(c *gc.C) {
+	authority, err := pkitest.NewTestAuthority()
+	c.Assert(err, jc.ErrorIsNil)
+
+	serverLeaf, err := authority.LeafRequestForGroup(pki.DefaultLeafGroup).
+		AddDNSNames("127.0.0.1").
+		AddIPAddresses(net.ParseIP("127.0.0.1")).
+		Commit()
+	c.Assert(err, jc.ErrorIsNil)
+
+	caCertPEM, caKeyPEM, err := authority.ToPemParts()
+	c.Assert(err, jc.ErrorIsNil)
+
+	clientCertPEM, clientKeyPEM, err := pki.NewClientCert(
+		"test-client", caCertPEM, caKeyPEM, time.Now().Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+
+	caPool := x509.NewCertPool()
+	c.Assert(caPool.AppendCertsFromPEM(caCertPEM), jc.IsTrue)
+
+	var seenCN string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenCN = r.TLS.PeerCertificates[0].Subject.CommonName
+		fmt.Fprint(w, "ok")
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{*serverLeaf.TLSCertificate()},
+		ClientCAs:    caPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+	c.Assert(err, jc.ErrorIsNil)
+
+	client := server.Client()
+	client.Transport.(*http.Transport).TLSClientConfig = &tls.Config{
+		RootCAs:      caPool,
+		Certificates: []tls.Certificate{clientCert},
+	}
+
+	resp, err := client.Get(server.URL)
+	c.Assert(err, jc.ErrorIsNil)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(body), gc.Equals, "ok")
+	c.Assert(seenCN, gc.Equals, "test-client")
+}
+
+func (s *ClientCertSuite) TestNewClientCertRejectsNonCA(c *gc.C) {
+	authority, err := pkitest.NewTestAuthority()
+	c.Assert(err, jc.ErrorIsNil)
+
+	serverLeaf, err := authority.LeafRequestForGroup(pki.DefaultLeafGroup).Commit()
+	c.Assert(err, jc.ErrorIsNil)
+	notCACertPEM, notCAKeyPEM, err := serverLeaf.ToPemParts()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, _, err = pki.NewClientCert("test-client", notCACertPEM, notCAKeyPEM, time.Now().Add(time.Hour))
+	c.Assert(err, gc.ErrorMatches, `.* is not a certificate authority not valid`)
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package pki
+
+import (
+	"crypto"
+	"crypto/x509"
+	"os"
+
+	"github.com/juju/errors"
+)
+
+// LoadCAFromFiles reads the CA certificate and private key PEM files at
+// certPath and keyPath and parses them, centralising the boilerplate and
+// error messages for the common case of a CA stored as a pair of PEM files
+// on disk.
+func LoadCAFromFiles(certPath, keyPath string) (*x509.Certificate, crypto.Signer, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, errors.NotFoundf("CA cert file %q", certPath)
+		}
+		return nil, nil, errors.Annotatef(err, "reading CA cert file %q", certPath)
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, errors.NotFoundf("CA key file %q", keyPath)
+		}
+		return nil, nil, errors.Annotatef(err, "reading CA key file %q", keyPath)
+	}
+
+	caCert, caSigner, err := unmarshalCAPemData(certPEM, keyPEM)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return caCert, caSigner, nil
+}
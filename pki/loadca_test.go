@@ -0,0 +1,59 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package pki_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/pki"
+	pkitest "github.com/juju/juju/pki/test"
+)
+
+type LoadCASuite struct{}
+
+var _ = gc.Suite(&LoadCASuite{})
+
+func (s *LoadCASuite) writeCAFiles(c *gc.C) (string, string) {
+	authority, err := pkitest.NewTestAuthority()
+	c.Assert(err, jc.ErrorIsNil)
+	certPEM, keyPEM, err := authority.ToPemParts()
+	c.Assert(err, jc.ErrorIsNil)
+
+	dir := c.MkDir()
+	certPath := filepath.Join(dir, "ca-cert.pem")
+	keyPath := filepath.Join(dir, "ca-key.pem")
+	c.Assert(os.WriteFile(certPath, certPEM, 0644), jc.ErrorIsNil)
+	c.Assert(os.WriteFile(keyPath, keyPEM, 0600), jc.ErrorIsNil)
+	return certPath, keyPath
+}
+
+func (s *LoadCASuite) TestLoadCAFromFiles(c *gc.C) {
+	certPath, keyPath := s.writeCAFiles(c)
+
+	cert, signer, err := pki.LoadCAFromFiles(certPath, keyPath)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cert.IsCA, jc.IsTrue)
+	c.Assert(signer, gc.NotNil)
+}
+
+func (s *LoadCASuite) TestLoadCAFromFilesMissingCert(c *gc.C) {
+	_, keyPath := s.writeCAFiles(c)
+
+	_, _, err := pki.LoadCAFromFiles(filepath.Join(c.MkDir(), "missing.pem"), keyPath)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `CA cert file ".*" not found`)
+}
+
+func (s *LoadCASuite) TestLoadCAFromFilesMissingKey(c *gc.C) {
+	certPath, _ := s.writeCAFiles(c)
+
+	_, _, err := pki.LoadCAFromFiles(certPath, filepath.Join(c.MkDir(), "missing.pem"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `CA key file ".*" not found`)
+}
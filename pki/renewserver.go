@@ -0,0 +1,73 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package pki
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// RenewServer reissues a server certificate ahead of expiry, copying the
+// Subject, DNSNames and IPAddresses of oldServerCertPEM into a fresh
+// certificate signed by the supplied CA with newExpiry. The old certificate
+// must have been signed by caCertPEM/caKeyPEM.
+func RenewServer(oldServerCertPEM, caCertPEM, caKeyPEM []byte, newExpiry time.Time) (certPEM, keyPEM []byte, err error) {
+	caCert, caSigner, err := unmarshalCAPemData(caCertPEM, caKeyPEM)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+
+	oldCerts, _, err := UnmarshalPemData(oldServerCertPEM)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if len(oldCerts) == 0 {
+		return nil, nil, errors.NotValidf("no certificate found")
+	}
+	oldCert := oldCerts[0]
+
+	if err := oldCert.CheckSignatureFrom(caCert); err != nil {
+		return nil, nil, errors.Annotate(err, "old certificate was not signed by the supplied CA")
+	}
+
+	signer, err := DefaultKeyProfile()
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+
+	template := &x509.Certificate{
+		Subject:     oldCert.Subject,
+		DNSNames:    oldCert.DNSNames,
+		IPAddresses: oldCert.IPAddresses,
+		NotBefore:   time.Now().Add(NotBeforeJitter),
+		NotAfter:    newExpiry,
+		KeyUsage:    oldCert.KeyUsage,
+		ExtKeyUsage: oldCert.ExtKeyUsage,
+	}
+	if err := assetTagCertificate(template); err != nil {
+		return nil, nil, errors.Annotate(err, "failed tagging renewed server certificate")
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, signer.Public(), caSigner)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "failed creating renewed server certificate")
+	}
+	newCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+
+	certStr, err := CertificateToPemString(DefaultPemHeaders, newCert)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	keyStr, err := SignerToPemString(signer)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return []byte(certStr), []byte(keyStr), nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package pki_test
+
+import (
+	"net"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/pki"
+	pkitest "github.com/juju/juju/pki/test"
+)
+
+type RenewServerSuite struct{}
+
+var _ = gc.Suite(&RenewServerSuite{})
+
+func (s *RenewServerSuite) TestRenewServerPreservesSANs(c *gc.C) {
+	authority, err := pkitest.NewTestAuthority()
+	c.Assert(err, jc.ErrorIsNil)
+
+	leaf, err := authority.LeafRequestForGroup(pki.DefaultLeafGroup).
+		AddDNSNames("juju-controller", "localhost").
+		AddIPAddresses(net.ParseIP("10.0.0.1")).
+		Commit()
+	c.Assert(err, jc.ErrorIsNil)
+
+	oldCertPEM, _, err := leaf.ToPemParts()
+	c.Assert(err, jc.ErrorIsNil)
+	caCertPEM, caKeyPEM, err := authority.ToPemParts()
+	c.Assert(err, jc.ErrorIsNil)
+
+	newExpiry := time.Now().AddDate(1, 0, 0)
+	newCertPEM, _, err := pki.RenewServer(oldCertPEM, caCertPEM, caKeyPEM, newExpiry)
+	c.Assert(err, jc.ErrorIsNil)
+
+	newCerts, _, err := pki.UnmarshalPemData(newCertPEM)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(newCerts, gc.HasLen, 1)
+	newCert := newCerts[0]
+
+	c.Assert(newCert.Subject.CommonName, gc.Equals, leaf.Certificate().Subject.CommonName)
+	c.Assert(pki.LeafHasDNSNames(pki.NewDefaultLeaf("", newCert, nil, nil), []string{"juju-controller", "localhost"}), jc.IsTrue)
+	c.Assert(newCert.IPAddresses, gc.HasLen, 1)
+	c.Assert(newCert.IPAddresses[0].Equal(net.ParseIP("10.0.0.1")), jc.IsTrue)
+	c.Assert(newCert.NotAfter.Year(), gc.Equals, newExpiry.Year())
+}
+
+func (s *RenewServerSuite) TestRenewServerRejectsWrongCA(c *gc.C) {
+	authority, err := pkitest.NewTestAuthority()
+	c.Assert(err, jc.ErrorIsNil)
+	leaf, err := authority.LeafRequestForGroup(pki.DefaultLeafGroup).Commit()
+	c.Assert(err, jc.ErrorIsNil)
+	oldCertPEM, _, err := leaf.ToPemParts()
+	c.Assert(err, jc.ErrorIsNil)
+
+	otherAuthority, err := pkitest.NewTestAuthority()
+	c.Assert(err, jc.ErrorIsNil)
+	otherCACertPEM, otherCAKeyPEM, err := otherAuthority.ToPemParts()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, _, err = pki.RenewServer(oldCertPEM, otherCACertPEM, otherCAKeyPEM, time.Now().AddDate(1, 0, 0))
+	c.Assert(err, gc.ErrorMatches, "old certificate was not signed by the supplied CA: .*")
+}
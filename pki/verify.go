@@ -0,0 +1,52 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package pki
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+var (
+	// ErrCertExpired indicates that Verify failed because the certificate
+	// has expired or is not yet valid, as opposed to being untrusted. A
+	// caller can use this to decide to renew the certificate rather than
+	// raise an alert.
+	ErrCertExpired = errors.New("certificate has expired or is not yet valid")
+
+	// ErrUntrustedCA indicates that Verify failed because the certificate
+	// was not signed by the trusted CA, which usually means operator
+	// intervention is needed rather than an automatic renewal.
+	ErrUntrustedCA = errors.New("certificate signed by unknown authority")
+)
+
+// Verify checks that cert chains up to caCert and is valid at now, returning
+// ErrCertExpired or ErrUntrustedCA (wrapped with the underlying x509 error,
+// so callers can use errors.Is to distinguish them) when it does not.
+func Verify(cert, caCert *x509.Certificate, now time.Time) error {
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:       roots,
+		CurrentTime: now,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err == nil {
+		return nil
+	}
+
+	switch e := err.(type) {
+	case x509.CertificateInvalidError:
+		if e.Reason == x509.Expired {
+			return fmt.Errorf("%s: %w", err, ErrCertExpired)
+		}
+	case x509.UnknownAuthorityError:
+		return fmt.Errorf("%s: %w", err, ErrUntrustedCA)
+	}
+	return errors.Trace(err)
+}
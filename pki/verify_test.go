@@ -0,0 +1,62 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package pki_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/pki"
+	pkitest "github.com/juju/juju/pki/test"
+)
+
+type VerifySuite struct{}
+
+var _ = gc.Suite(&VerifySuite{})
+
+func (s *VerifySuite) TestVerifyValid(c *gc.C) {
+	authority, err := pkitest.NewTestAuthority()
+	c.Assert(err, jc.ErrorIsNil)
+	leaf, err := authority.LeafRequestForGroup(pki.DefaultLeafGroup).Commit()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = pki.Verify(leaf.Certificate(), authority.Certificate(), time.Now())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *VerifySuite) TestVerifyExpired(c *gc.C) {
+	authority, err := pkitest.NewTestAuthority()
+	c.Assert(err, jc.ErrorIsNil)
+	leaf, err := authority.LeafRequestForGroup(pki.DefaultLeafGroup).Commit()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = pki.Verify(leaf.Certificate(), authority.Certificate(), leaf.Certificate().NotAfter.Add(time.Hour))
+	c.Assert(errors.Is(err, pki.ErrCertExpired), jc.IsTrue)
+}
+
+func (s *VerifySuite) TestVerifyNotYetValid(c *gc.C) {
+	authority, err := pkitest.NewTestAuthority()
+	c.Assert(err, jc.ErrorIsNil)
+	leaf, err := authority.LeafRequestForGroup(pki.DefaultLeafGroup).Commit()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = pki.Verify(leaf.Certificate(), authority.Certificate(), leaf.Certificate().NotBefore.Add(-time.Hour))
+	c.Assert(errors.Is(err, pki.ErrCertExpired), jc.IsTrue)
+}
+
+func (s *VerifySuite) TestVerifyUntrustedCA(c *gc.C) {
+	authority, err := pkitest.NewTestAuthority()
+	c.Assert(err, jc.ErrorIsNil)
+	leaf, err := authority.LeafRequestForGroup(pki.DefaultLeafGroup).Commit()
+	c.Assert(err, jc.ErrorIsNil)
+
+	otherAuthority, err := pkitest.NewTestAuthority()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = pki.Verify(leaf.Certificate(), otherAuthority.Certificate(), time.Now())
+	c.Assert(errors.Is(err, pki.ErrUntrustedCA), jc.IsTrue)
+}
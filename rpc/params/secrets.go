@@ -59,6 +59,9 @@ type CreateSecretArg struct {
 	URI *string `json:"uri,omitempty"`
 	// OwnerTag is the owner of the secret.
 	OwnerTag string `json:"owner-tag"`
+	// Ephemeral indicates that the secret should be removed
+	// automatically when its owning unit is removed.
+	Ephemeral bool `json:"ephemeral,omitempty"`
 }
 
 // UpdateSecretArgs holds args for updating secrets.
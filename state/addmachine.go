@@ -130,6 +130,13 @@ func (st *State) AddMachineInsideMachine(template MachineTemplate, parentId stri
 
 // AddMachine adds a machine with the given series and jobs.
 // It is deprecated and around for testing purposes only.
+//
+// Machine ids are allocated via the "machine" sequence document
+// (see addMachineOps/sequence), so concurrent callers of AddMachine,
+// AddMachines, AddOneMachine, AddMachineInsideMachine and
+// AddMachineInsideNewMachine never collide on an id: sequence increments
+// the counter with a single atomic findAndModify before the machine's
+// transaction is built, so each caller receives a distinct value.
 func (st *State) AddMachine(base Base, jobs ...MachineJob) (*Machine, error) {
 	ms, err := st.AddMachines(MachineTemplate{
 		Base: base,
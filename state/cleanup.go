@@ -1214,6 +1214,13 @@ func (st *State) cleanupRemovedUnit(unitId string, cleanupArgs []bson.Raw) error
 		}
 		logger.Warningf("could not cleanup payload for unit %v during cleanup of removed unit: %v", unitId, err)
 	}
+
+	if err := st.removeOwnerEphemeralSecrets(names.NewUnitTag(unitId)); err != nil {
+		if !force {
+			return errors.Trace(err)
+		}
+		logger.Warningf("could not cleanup ephemeral secrets for unit %v during cleanup of removed unit: %v", unitId, err)
+	}
 	return nil
 }
 
@@ -1199,6 +1199,13 @@ func (st *State) ScheduleForceCleanup(kind cleanupKind, name string, maxWait tim
 	st.scheduleForceCleanup(kind, name, maxWait)
 }
 
+// RunWithRetryForTest exposes runWithRetry for tests exercising its
+// retry-on-abort behaviour directly, without needing to provoke a real
+// concurrent modification.
+func (st *State) RunWithRetryForTest(ops func() ([]txn.Op, error), attempts int) error {
+	return st.runWithRetry(ops, attempts)
+}
+
 func GetCollectionCappedInfo(coll *mgo.Collection) (bool, int, error) {
 	return getCollectionCappedInfo(coll)
 }
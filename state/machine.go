@@ -4,6 +4,8 @@
 package state
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -28,6 +30,7 @@ import (
 	"github.com/juju/juju/core/instance"
 	"github.com/juju/juju/core/model"
 	"github.com/juju/juju/core/network"
+	"github.com/juju/juju/core/series"
 	"github.com/juju/juju/core/status"
 	"github.com/juju/juju/environs/bootstrap"
 	"github.com/juju/juju/mongo"
@@ -172,6 +175,19 @@ func (m *Machine) Base() Base {
 	return m.doc.Base
 }
 
+// Series returns the OS series corresponding to the machine's base, for
+// callers that still key off series rather than base. The machine's base
+// is set once when the machine is added and from then on only changes
+// via UpdateMachineSeries, so Series changes in step with it; there is no
+// separate, independently-settable series value to keep in sync.
+func (m *Machine) Series() (string, error) {
+	s, err := series.GetSeriesFromChannel(m.doc.Base.OS, m.doc.Base.Channel)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return s, nil
+}
+
 // ContainerType returns the type of container hosting this machine.
 func (m *Machine) ContainerType() instance.ContainerType {
 	return instance.ContainerType(m.doc.ContainerType)
@@ -364,6 +380,11 @@ func (d *ModelInstanceData) InstanceNames(machineID string) (instance.Id, string
 // string representation that is safe to use as a file name. The returned name
 // will be different from other Tag values returned by any other entities
 // from the same state.
+//
+// Callers that only have an Id() and want the "machine-<id>" form (for
+// example "machine-3", or "machine-3-lxd-0" for a container) should call
+// Tag().String() rather than concatenating the prefix by hand, since the
+// latter doesn't round-trip correctly for container ids.
 func (m *Machine) Tag() names.Tag {
 	return m.MachineTag()
 }
@@ -519,7 +540,11 @@ func checkVersionValidity(v version.Binary) error {
 }
 
 // SetAgentVersion sets the version of juju that the agent is
-// currently running.
+// currently running. The companion getter is AgentTools, which returns
+// an error satisfying errors.IsNotFound if no version has been set yet,
+// and the write itself is rejected with stateerrors.ErrDead once the
+// machine's life is no longer Alive/Dying, via the notDeadDoc assertion
+// in setAgentVersionOps.
 func (m *Machine) SetAgentVersion(v version.Binary) (err error) {
 	defer errors.DeferredAnnotatef(&err, "cannot set agent version for machine %v", m)
 	ops, tools, err := m.setAgentVersionOps(v)
@@ -684,6 +709,57 @@ func (m *Machine) DestroyWithContainers() error {
 	return m.advanceLifecycle(Dying, false, true, 0)
 }
 
+// DestroyWithUnits sets the machine lifecycle to Dying if it is Alive,
+// and sets every Alive unit assigned to the machine to Dying in the
+// same transaction. Unlike Destroy, it does not fail or return an error
+// if the machine has assigned units; it is intended for callers that
+// want the machine and its units to start dying together, rather than
+// having to wait for the units to be destroyed individually first. If
+// the set of units assigned to the machine changes concurrently, or any
+// unit is no longer Alive, the whole transaction is aborted and retried
+// against the latest state.
+func (m *Machine) DestroyWithUnits() error {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		fresh, err := m.st.Machine(m.doc.Id)
+		if errors.IsNotFound(err) {
+			return nil, jujutxn.ErrNoOperations
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if fresh.doc.Life != Alive {
+			return nil, jujutxn.ErrNoOperations
+		}
+		units, err := fresh.Units()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		principalNames := append([]string(nil), fresh.doc.Principals...)
+		ops := []txn.Op{{
+			C:      machinesC,
+			Id:     fresh.doc.DocID,
+			Assert: append(isAliveDoc, advanceLifecycleUnitAsserts(principalNames)),
+			Update: bson.D{{"$set", bson.D{{"life", Dying}}}},
+		}}
+		for _, u := range units {
+			if u.Life() != Alive {
+				continue
+			}
+			ops = append(ops, txn.Op{
+				C:      unitsC,
+				Id:     u.doc.DocID,
+				Assert: isAliveDoc,
+				Update: bson.D{{"$set", bson.D{{"life", Dying}}}},
+			})
+		}
+		return ops, nil
+	}
+	if err := m.st.db().Run(buildTxn); err != nil {
+		return errors.Annotatef(err, "cannot destroy machine %s with units", m)
+	}
+	m.doc.Life = Dying
+	return nil
+}
+
 // ForceDestroy queues the machine for complete removal, including the
 // destruction of all units and containers on the machine.
 func (m *Machine) ForceDestroy(maxWait time.Duration) error {
@@ -1153,6 +1229,13 @@ func (m *Machine) removeOps() ([]txn.Op, error) {
 	if m.doc.Life != Dead {
 		return nil, fmt.Errorf("machine is not dead")
 	}
+	units, err := m.Units()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(units) > 0 {
+		return nil, fmt.Errorf("machine still has assigned units")
+	}
 	ops := []txn.Op{
 		{
 			C:      machinesC,
@@ -1255,6 +1338,30 @@ func (m *Machine) Refresh() error {
 	return nil
 }
 
+// MachineInfo is a plain, JSON-serializable snapshot of a Machine. It
+// exists so that layers such as the API server can expose machine state
+// without reaching into State's unexported fields.
+type MachineInfo struct {
+	Id         string `json:"id"`
+	InstanceId string `json:"instance-id"`
+	Life       string `json:"life"`
+}
+
+// MarshalJSON implements json.Marshaler, encoding m as a MachineInfo.
+// Unlike InstanceId, an unprovisioned machine serializes its instance id
+// as an empty string rather than producing an error.
+func (m *Machine) MarshalJSON() ([]byte, error) {
+	instId, err := m.InstanceId()
+	if err != nil && !errors.IsNotProvisioned(err) {
+		return nil, errors.Trace(err)
+	}
+	return json.Marshal(MachineInfo{
+		Id:         m.Id(),
+		InstanceId: string(instId),
+		Life:       string(m.Life().Value()),
+	})
+}
+
 // InstanceId returns the provider specific instance id for this
 // machine, or a NotProvisionedError, if not set.
 func (m *Machine) InstanceId() (instance.Id, error) {
@@ -1391,6 +1498,17 @@ func (m *Machine) Units() (units []*Unit, err error) {
 	return units, nil
 }
 
+// AssignUnit places u on m, recording the placement by setting the
+// unit's machine id. Both m and u must be Alive, and u must not already
+// be assigned to a different machine. It is a thin, machine-side
+// counterpart to Unit.AssignToMachine, which does the actual work
+// (including any storage provisioning the placement requires); it
+// exists so that callers coming from the machine, rather than the
+// unit, have a single obvious place to record placement.
+func (m *Machine) AssignUnit(u *Unit) error {
+	return u.AssignToMachine(m)
+}
+
 // SetProvisioned stores the machine's provider-specific details in the
 // database. These details are used to infer that the machine has
 // been provisioned.
@@ -1402,7 +1520,10 @@ func (m *Machine) Units() (units []*Unit, err error) {
 // instance will be able to act for that machine.
 //
 // Once set, the instance id cannot be changed. A non-empty instance id
-// will be detected as a provisioned machine.
+// will be detected as a provisioned machine. The write is atomic and
+// only succeeds while the machine is alive and not already provisioned;
+// CheckProvisioned can then be used to confirm the nonce a caller holds
+// still matches the one recorded here.
 func (m *Machine) SetProvisioned(
 	id instance.Id,
 	displayName string,
@@ -1415,6 +1536,20 @@ func (m *Machine) SetProvisioned(
 		return fmt.Errorf("instance id and nonce cannot be empty")
 	}
 
+	// The provisioner retries SetProvisioned if it loses the response to
+	// a call that actually succeeded, so a repeat call with the instance
+	// id already recorded is expected and should be a cheap no-op rather
+	// than running the transaction again, which would only fail with
+	// "already set". InstanceId queries instanceDataC directly, so this
+	// check is accurate even if m.doc is stale because the in-memory
+	// Machine predates the original, successful call; update m.doc.Nonce
+	// the same way the normal path below does, so a stale in-memory
+	// Machine reflects the nonce that was actually recorded.
+	if existing, err := m.InstanceId(); err == nil && existing == id {
+		m.doc.Nonce = nonce
+		return nil
+	}
+
 	coll, closer := m.st.db().GetCollection(instanceDataC)
 	defer closer()
 	count, err := coll.Find(bson.D{{"instanceid", id}}).Count()
@@ -1444,21 +1579,23 @@ func (m *Machine) SetProvisioned(
 		AvailZone:      characteristics.AvailabilityZone,
 	}
 
-	ops := []txn.Op{
-		{
-			C:      machinesC,
-			Id:     m.doc.DocID,
-			Assert: append(isAliveDoc, bson.DocElem{Name: "nonce", Value: ""}),
-			Update: bson.D{{"$set", bson.D{{"nonce", nonce}}}},
-		}, {
-			C:      instanceDataC,
-			Id:     m.doc.DocID,
-			Assert: txn.DocMissing,
-			Insert: instData,
-		},
+	buildOps := func() ([]txn.Op, error) {
+		return []txn.Op{
+			{
+				C:      machinesC,
+				Id:     m.doc.DocID,
+				Assert: append(isAliveDoc, bson.DocElem{Name: "nonce", Value: ""}),
+				Update: bson.D{{"$set", bson.D{{"nonce", nonce}}}},
+			}, {
+				C:      instanceDataC,
+				Id:     m.doc.DocID,
+				Assert: txn.DocMissing,
+				Insert: instData,
+			},
+		}, nil
 	}
 
-	if err = m.st.db().RunTransaction(ops); err == nil {
+	if err = m.st.runWithRetry(buildOps, 3); err == nil {
 		m.doc.Nonce = nonce
 		return nil
 	} else if err != txn.ErrAborted {
@@ -1471,6 +1608,71 @@ func (m *Machine) SetProvisioned(
 	return fmt.Errorf("already set")
 }
 
+// SetInstanceIds sets the provider instance id for each of the given
+// machines in a single transaction, so that either all of them are
+// recorded or none are. It is intended for providers that start many
+// instances in one call and want to avoid the cost of a separate
+// round-trip per machine.
+//
+// Unlike SetProvisioned, no nonce is recorded and the machine's existing
+// nonce is neither checked nor changed. Each machine's instance data is
+// inserted with a DocMissing assertion, so if any machine already has
+// instance data (for example from SetProvisioned) the whole transaction
+// is aborted and an error is returned.
+//
+// Machines that are not alive are not included in the transaction; the
+// returned map reports the error for each such machine, keyed by
+// machine id. A nil map means every machine was updated successfully.
+func (st *State) SetInstanceIds(ids map[string]string) (map[string]error, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var ops []txn.Op
+	var failures map[string]error
+	for machineId, instId := range ids {
+		m, err := st.Machine(machineId)
+		if errors.IsNotFound(err) {
+			if failures == nil {
+				failures = make(map[string]error)
+			}
+			failures[machineId] = err
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if m.Life() != Alive {
+			if failures == nil {
+				failures = make(map[string]error)
+			}
+			failures[machineId] = machineNotAliveErr
+			continue
+		}
+		ops = append(ops, txn.Op{
+			C:      instanceDataC,
+			Id:     m.doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: &instanceData{
+				DocID:      m.doc.DocID,
+				MachineId:  m.doc.Id,
+				InstanceId: instance.Id(instId),
+				ModelUUID:  m.doc.ModelUUID,
+			},
+		}, txn.Op{
+			C:      machinesC,
+			Id:     m.doc.DocID,
+			Assert: isAliveDoc,
+		})
+	}
+	if len(ops) == 0 {
+		return failures, nil
+	}
+	if err := st.db().RunTransaction(ops); err != nil {
+		return nil, errors.Annotate(err, "cannot set instance ids")
+	}
+	return failures, nil
+}
+
 // SetInstanceInfo is used to provision a machine and in one step sets its
 // instance ID, nonce, hardware characteristics, add link-layer devices and set
 // their addresses as needed.  After, set charm profiles if needed.
@@ -1916,6 +2118,25 @@ func (m *Machine) setConstraintsOps(cons constraints.Value) ([]txn.Op, error) {
 	return ops, nil
 }
 
+// isDeadAndExists reports whether the machine's current document still
+// exists and has Life == Dead. A machine that has already been removed
+// reports false, leaving not-found errors to be surfaced by whatever
+// query subsequently fails to find it.
+func (m *Machine) isDeadAndExists() (bool, error) {
+	coll, closer := m.st.db().GetCollection(machinesC)
+	defer closer()
+	var doc struct {
+		Life Life `bson:"life"`
+	}
+	err := coll.FindId(m.doc.DocID).One(&doc)
+	if err == mgo.ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	return doc.Life == Dead, nil
+}
+
 // Status returns the status of the machine.
 func (m *Machine) Status() (status.StatusInfo, error) {
 	mStatus, err := getStatus(m.st.db(), m.globalKey(), "machine")
@@ -1925,8 +2146,70 @@ func (m *Machine) Status() (status.StatusInfo, error) {
 	return mStatus, nil
 }
 
+// agentAlivePollInterval is how often WaitAgentAliveCtx re-checks the
+// machine's agent status while waiting for it to come up.
+var agentAlivePollInterval = 1 * time.Second
+
+// WaitAgentAliveConfig controls the polling behaviour of
+// WaitAgentAliveCtxWithConfig. It is most useful in tests, where a
+// shorter PollInterval lets a test notice the agent coming up promptly
+// instead of waiting out the package default.
+type WaitAgentAliveConfig struct {
+	// PollInterval is how often to re-check the machine's agent status
+	// while waiting for it to come up. If zero, agentAlivePollInterval
+	// is used.
+	PollInterval time.Duration
+}
+
+// WaitAgentAliveCtx blocks until the machine's agent reports itself as
+// started, or ctx is done, whichever happens first. It returns ctx.Err()
+// promptly on cancellation or deadline, and nil once the agent is alive.
+func (m *Machine) WaitAgentAliveCtx(ctx context.Context) error {
+	return m.WaitAgentAliveCtxWithConfig(ctx, WaitAgentAliveConfig{})
+}
+
+// WaitAgentAliveCtxWithConfig is WaitAgentAliveCtx with a tunable poll
+// interval, for callers such as tests that want to shrink the interval
+// rather than wait out the package default.
+func (m *Machine) WaitAgentAliveCtxWithConfig(ctx context.Context, cfg WaitAgentAliveConfig) error {
+	pollInterval := cfg.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = agentAlivePollInterval
+	}
+	clk := m.st.clock()
+	for {
+		statusInfo, err := m.Status()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if statusInfo.Status == status.Started {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clk.After(pollInterval):
+		}
+	}
+}
+
+// WaitAgentAlive blocks until the machine's agent reports itself as
+// started, or timeout elapses, whichever happens first. It is a thin
+// wrapper around WaitAgentAliveCtx for callers that don't need to thread
+// a context through.
+func (m *Machine) WaitAgentAlive(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return m.WaitAgentAliveCtx(ctx)
+}
+
 // SetStatus sets the status of the machine.
 func (m *Machine) SetStatus(statusInfo status.StatusInfo) error {
+	if dead, err := m.isDeadAndExists(); err != nil {
+		return errors.Trace(err)
+	} else if dead {
+		return errors.Errorf("cannot set status of dead machine %q", m.Id())
+	}
 	switch statusInfo.Status {
 	case status.Started, status.Stopped:
 	case status.Error:
@@ -1970,7 +2253,10 @@ func (m *Machine) StatusHistory(filter status.StatusHistoryFilter) ([]status.Sta
 	return statusHistory(args)
 }
 
-// Clean returns true if the machine does not have any deployed units or containers.
+// Clean returns true if the machine does not have any deployed units or
+// containers. It starts out true for a newly added machine and is
+// cleared permanently the first time a unit is assigned to it, even if
+// that unit is later removed.
 func (m *Machine) Clean() bool {
 	return m.doc.Clean
 }
@@ -1998,6 +2284,9 @@ func (m *Machine) SetSupportedContainers(containers []instance.ContainerType) (e
 		if container == instance.NONE {
 			return fmt.Errorf("%q is not a valid container type", container)
 		}
+		if _, err := instance.ParseContainerType(string(container)); err != nil {
+			return err
+		}
 	}
 	if err = m.updateSupportedContainers(containers); err != nil {
 		return err
@@ -4,6 +4,8 @@
 package state_test
 
 import (
+	"context"
+	"encoding/json"
 	"sort"
 	"strings"
 	"time"
@@ -662,6 +664,41 @@ func (s *MachineSuite) TestRemove(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (s *MachineSuite) TestRemoveFailsIfNotDead(c *gc.C) {
+	err := s.machine.Remove()
+	c.Assert(err, gc.ErrorMatches, "cannot remove machine 1: machine is not dead")
+
+	err = s.machine.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.Remove()
+	c.Assert(err, gc.ErrorMatches, "cannot remove machine 1: machine is not dead")
+}
+
+func (s *MachineSuite) TestRemoveFailsIfUnitsStillAssigned(c *gc.C) {
+	err := s.machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Insert a unit doc referencing the machine directly, bypassing the
+	// usual AddUnit/AssignToMachine lifecycle checks: a machine can never
+	// legitimately reach Dead while units remain assigned to it, so this
+	// simulates the only way the guard in Remove could ever be tripped.
+	state.RunTransaction(c, s.State, []txn.Op{{
+		C:      state.UnitsC,
+		Id:     state.DocID(s.State, "wordpress/0"),
+		Assert: txn.DocMissing,
+		Insert: bson.M{
+			"name":        "wordpress/0",
+			"model-uuid":  s.State.ModelUUID(),
+			"application": "wordpress",
+			"life":        state.Alive,
+			"machineid":   s.machine.Id(),
+		},
+	}})
+
+	err = s.machine.Remove()
+	c.Assert(err, gc.ErrorMatches, "cannot remove machine 1: machine still has assigned units")
+}
+
 func (s *MachineSuite) TestRemoveAbort(c *gc.C) {
 	err := s.machine.EnsureDead()
 	c.Assert(err, jc.ErrorIsNil)
@@ -964,6 +1001,15 @@ func (s *MachineSuite) TestMachineSetCheckProvisioned(c *gc.C) {
 	c.Assert(s.machine.CheckProvisioned("not-really"), jc.IsFalse)
 }
 
+func (s *MachineSuite) TestSetProvisionedEmptyInstanceIdLeavesMachineUnprovisioned(c *gc.C) {
+	err := s.machine.SetProvisioned(instance.Id(""), "", "fake_nonce", nil)
+	c.Assert(err, gc.ErrorMatches, `cannot set instance data for machine "1": instance id and nonce cannot be empty`)
+
+	_, err = s.machine.InstanceId()
+	c.Assert(err, jc.Satisfies, errors.IsNotProvisioned)
+	c.Assert(s.machine.CheckProvisioned("fake_nonce"), jc.IsFalse)
+}
+
 func (s *MachineSuite) TestSetProvisionedDupInstanceId(c *gc.C) {
 	var logWriter loggo.TestWriter
 	c.Assert(loggo.RegisterWriter("dupe-test", &logWriter), gc.IsNil)
@@ -986,6 +1032,122 @@ func (s *MachineSuite) TestSetProvisionedDupInstanceId(c *gc.C) {
 	c.Assert(found, jc.IsTrue)
 }
 
+func (s *MachineSuite) TestSetProvisionedSameInstanceIdIsNoOp(c *gc.C) {
+	err := s.machine.SetProvisioned("umbrella/0", "snowflake", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Retrying with the exact same instance id, as the provisioner does
+	// after losing the response to a call that actually succeeded, must
+	// not fail even though the machine already has a nonce set: it's a
+	// no-op, not a second provisioning attempt.
+	err = s.machine.SetProvisioned("umbrella/0", "snowflake", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// It must also be a no-op for a Machine obtained before the first
+	// call committed, to rule out the check trusting a stale in-memory
+	// doc instead of querying the stored instance data.
+	stale, err := s.State.Machine(s.machine.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	err = stale.SetProvisioned("umbrella/0", "snowflake", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	id, err := s.machine.InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(id), gc.Equals, "umbrella/0")
+
+	// CheckProvisioned reads m.doc.Nonce directly rather than querying
+	// instanceDataC, so the no-op must also have brought the stale
+	// Machine's in-memory doc up to date, not just returned success.
+	c.Assert(stale.CheckProvisioned("fake_nonce"), jc.IsTrue)
+}
+
+func (s *MachineSuite) TestSetProvisionedChangedInstanceIdStillFails(c *gc.C) {
+	err := s.machine.SetProvisioned("umbrella/0", "", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// A genuinely different instance id is not the no-op retry case, so
+	// the usual "already set" behaviour is preserved.
+	err = s.machine.SetProvisioned("umbrella/1", "", "another_nonce", nil)
+	c.Assert(err, gc.ErrorMatches, `cannot set instance data for machine "1": already set`)
+}
+
+func (s *MachineSuite) TestSetInstanceIdsBatch(c *gc.C) {
+	m1, err := s.State.AddMachine(state.UbuntuBase("12.10"), state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	m2, err := s.State.AddMachine(state.UbuntuBase("12.10"), state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	failures, err := s.State.SetInstanceIds(map[string]string{
+		m1.Id(): "inst-1",
+		m2.Id(): "inst-2",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(failures, gc.IsNil)
+
+	m1, err = s.State.Machine(m1.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	id, err := m1.InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(id), gc.Equals, "inst-1")
+
+	m2, err = s.State.Machine(m2.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	id, err = m2.InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(id), gc.Equals, "inst-2")
+}
+
+func (s *MachineSuite) TestSetInstanceIdsMixedBatchDyingMachine(c *gc.C) {
+	m1, err := s.State.AddMachine(state.UbuntuBase("12.10"), state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	m2, err := s.State.AddMachine(state.UbuntuBase("12.10"), state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m2.Destroy(), jc.ErrorIsNil)
+
+	failures, err := s.State.SetInstanceIds(map[string]string{
+		m1.Id(): "inst-1",
+		m2.Id(): "inst-2",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(failures, gc.HasLen, 1)
+	c.Assert(failures[m2.Id()], gc.ErrorMatches, "machine is not found or not alive")
+
+	m1, err = s.State.Machine(m1.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	id, err := m1.InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(id), gc.Equals, "inst-1")
+
+	m2, err = s.State.Machine(m2.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = m2.InstanceId()
+	c.Assert(err, jc.Satisfies, errors.IsNotProvisioned)
+}
+
+func (s *MachineSuite) TestWaitAgentAliveCtxCancelled(c *gc.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := s.machine.WaitAgentAliveCtx(ctx)
+	c.Assert(err, gc.Equals, context.Canceled)
+}
+
+func (s *MachineSuite) TestWaitAgentAliveCtxWithConfigShortPollInterval(c *gc.C) {
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		c.Check(s.machine.SetStatus(status.StatusInfo{Status: status.Started}), jc.ErrorIsNil)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), coretesting.LongWait)
+	defer cancel()
+	start := time.Now()
+	err := s.machine.WaitAgentAliveCtxWithConfig(ctx, state.WaitAgentAliveConfig{
+		PollInterval: time.Millisecond,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(time.Since(start) < coretesting.LongWait, jc.IsTrue)
+}
+
 func (s *MachineSuite) TestMachineSetInstanceInfoFailureDoesNotProvision(c *gc.C) {
 	assertNotProvisioned := func() {
 		c.Assert(s.machine.CheckProvisioned("fake_nonce"), jc.IsFalse)
@@ -1226,6 +1388,115 @@ func sortedUnitNames(units []*state.Unit) []string {
 	return names
 }
 
+func (s *MachineSuite) TestAssignUnit(c *gc.C) {
+	app := s.AddTestingApplication(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := app.AddUnit(state.AddUnitParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.machine.AssignUnit(unit)
+	c.Assert(err, jc.ErrorIsNil)
+
+	mid, err := unit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(mid, gc.Equals, s.machine.Id())
+}
+
+func (s *MachineSuite) TestAssignUnitAlreadyAssignedElsewhere(c *gc.C) {
+	other, err := s.State.AddMachine(state.UbuntuBase("12.10"), state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	app := s.AddTestingApplication(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := app.AddUnit(state.AddUnitParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = other.AssignUnit(unit)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.machine.AssignUnit(unit)
+	c.Assert(err, gc.ErrorMatches, `cannot assign unit "wordpress/0" to machine .*: unit is already assigned to a machine`)
+}
+
+func (s *MachineSuite) TestAssignUnitToDyingMachine(c *gc.C) {
+	m, err := s.State.AddMachine(state.UbuntuBase("12.10"), state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = m.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.Life(), gc.Equals, state.Dying)
+
+	app := s.AddTestingApplication(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := app.AddUnit(state.AddUnitParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = m.AssignUnit(unit)
+	c.Assert(err, gc.ErrorMatches, `cannot assign unit "wordpress/0" to machine .*: machine is not found or not alive`)
+}
+
+func (s *MachineSuite) TestSeries(c *gc.C) {
+	m, err := s.State.AddMachine(state.UbuntuBase("20.04"), state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	series, err := m.Series()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(series, gc.Equals, "focal")
+}
+
+func (s *MachineSuite) TestSeriesTracksUpdateMachineSeries(c *gc.C) {
+	mach := s.setupTestUpdateMachineSeries(c)
+
+	series, err := mach.Series()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(series, gc.Equals, "precise")
+
+	err = mach.UpdateMachineSeries(state.UbuntuBase("22.04"))
+	c.Assert(err, jc.ErrorIsNil)
+	err = mach.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+
+	series, err = mach.Series()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(series, gc.Equals, "jammy")
+}
+
+func (s *MachineSuite) TestDestroyWithUnitsCascadesToUnits(c *gc.C) {
+	app := s.AddTestingApplication(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit1, err := app.AddUnit(state.AddUnitParams{})
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit1.AssignToMachine(s.machine)
+	c.Assert(err, jc.ErrorIsNil)
+	unit2, err := app.AddUnit(state.AddUnitParams{})
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit2.AssignToMachine(s.machine)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.machine.DestroyWithUnits()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine.Life(), gc.Equals, state.Dying)
+
+	err = unit1.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unit1.Life(), gc.Equals, state.Dying)
+
+	err = unit2.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unit2.Life(), gc.Equals, state.Dying)
+}
+
+func (s *MachineSuite) TestDestroyWithUnitsContention(c *gc.C) {
+	app := s.AddTestingApplication(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := app.AddUnit(state.AddUnitParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	perturb := jujutxn.TestHook{
+		Before: func() { c.Assert(unit.AssignToMachine(s.machine), gc.IsNil) },
+		After:  func() { c.Assert(unit.UnassignFromMachine(), gc.IsNil) },
+	}
+	state.SetMaxTxnAttempts(c, s.State, 3)
+	defer state.SetTestHooks(c, s.State, perturb, perturb, perturb).Check()
+
+	err = s.machine.DestroyWithUnits()
+	c.Assert(err, gc.ErrorMatches, `cannot destroy machine 1 with units: state changing too quickly; try again soon`)
+}
+
 func (s *MachineSuite) assertMachineDirtyAfterAddingUnit(c *gc.C) (*state.Machine, *state.Application, *state.Unit) {
 	m, err := s.State.AddMachine(state.UbuntuBase("12.10"), state.JobHostUnits)
 	c.Assert(err, jc.ErrorIsNil)
@@ -1302,6 +1573,88 @@ func (s *MachineSuite) TestWatchMachine(c *gc.C) {
 	testing.NewNotifyWatcherC(c, w).AssertOneChange()
 }
 
+func (s *MachineSuite) TestMarshalJSONUnprovisioned(c *gc.C) {
+	data, err := json.Marshal(s.machine)
+	c.Assert(err, jc.ErrorIsNil)
+
+	var info state.MachineInfo
+	c.Assert(json.Unmarshal(data, &info), jc.ErrorIsNil)
+	c.Assert(info, jc.DeepEquals, state.MachineInfo{
+		Id:         s.machine.Id(),
+		InstanceId: "",
+		Life:       "alive",
+	})
+}
+
+func (s *MachineSuite) TestMarshalJSONProvisioned(c *gc.C) {
+	err := s.machine.SetProvisioned(instance.Id("i-foo"), "", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	data, err := json.Marshal(s.machine)
+	c.Assert(err, jc.ErrorIsNil)
+
+	var info state.MachineInfo
+	c.Assert(json.Unmarshal(data, &info), jc.ErrorIsNil)
+	c.Assert(info, jc.DeepEquals, state.MachineInfo{
+		Id:         s.machine.Id(),
+		InstanceId: "i-foo",
+		Life:       "alive",
+	})
+}
+
+func (s *MachineSuite) TestWatchLife(c *gc.C) {
+	w := s.machine.WatchLife()
+	defer testing.AssertStop(c, w)
+
+	assertLife := func(expect state.Life) {
+		select {
+		case got, ok := <-w.Changes():
+			c.Assert(ok, jc.IsTrue)
+			c.Assert(got, gc.Equals, expect)
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for %v", expect)
+		}
+	}
+	assertNoChange := func() {
+		select {
+		case got := <-w.Changes():
+			c.Fatalf("unexpected change: %v", got)
+		case <-time.After(coretesting.ShortWait):
+		}
+	}
+
+	// Initial event.
+	assertLife(state.Alive)
+
+	err := s.machine.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	assertLife(state.Dying)
+
+	// EnsureDead from Dying delivers exactly one more event.
+	err = s.machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	assertLife(state.Dead)
+	assertNoChange()
+}
+
+func (s *MachineSuite) TestWatchInstanceId(c *gc.C) {
+	w := s.machine.WatchInstanceId()
+	defer testing.AssertStop(c, w)
+
+	// Initial event.
+	wc := testing.NewNotifyWatcherC(c, w)
+	wc.AssertOneChange()
+
+	// Provisioning the machine delivers a change.
+	err := s.machine.SetProvisioned(instance.Id("i-foo"), "", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	instId, err := s.machine.InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instId, gc.Equals, instance.Id("i-foo"))
+}
+
 func (s *MachineSuite) TestWatchDiesOnStateClose(c *gc.C) {
 	// This test is testing logic in watcher.entityWatcher, which
 	// is also used by:
@@ -2444,6 +2797,15 @@ func (s *MachineSuite) TestSetSupportedContainerTypeNoneIsError(c *gc.C) {
 	assertSupportedContainersUnknown(c, machine)
 }
 
+func (s *MachineSuite) TestSetSupportedContainerTypeUnknownIsError(c *gc.C) {
+	machine, err := s.State.AddMachine(state.UbuntuBase("12.10"), state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = machine.SetSupportedContainers([]instance.ContainerType{instance.ContainerType("bogus")})
+	c.Assert(err, gc.ErrorMatches, `invalid container type "bogus"`)
+	assertSupportedContainersUnknown(c, machine)
+}
+
 func (s *MachineSuite) TestSupportsNoContainersOverwritesExisting(c *gc.C) {
 	machine := s.addMachineWithSupportedContainer(c, instance.LXD)
 
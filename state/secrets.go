@@ -32,8 +32,9 @@ const LabelExists = errors.ConstError("label exists")
 type CreateSecretParams struct {
 	UpdateSecretParams
 
-	Version int
-	Owner   names.Tag
+	Version   int
+	Owner     names.Tag
+	Ephemeral bool
 }
 
 // UpdateSecretParams are used to update a secret.
@@ -103,6 +104,10 @@ type secretMetadataDoc struct {
 
 	RotatePolicy string `bson:"rotate-policy"`
 
+	// Ephemeral is true if the secret should be removed
+	// automatically when its owning unit is removed.
+	Ephemeral bool `bson:"ephemeral"`
+
 	CreateTime time.Time `bson:"create-time"`
 	UpdateTime time.Time `bson:"update-time"`
 }
@@ -162,6 +167,7 @@ func (s *secretsStore) secretMetadataDoc(uri *secrets.URI, p *CreateSecretParams
 		DocID:      uri.ID,
 		Version:    p.Version,
 		OwnerTag:   p.Owner.String(),
+		Ephemeral:  p.Ephemeral,
 		CreateTime: now,
 		UpdateTime: now,
 	}
@@ -470,6 +476,7 @@ func (s *secretsStore) toSecretMetadata(doc *secretMetadataDoc, nextRotateTime *
 		Description:      doc.Description,
 		Label:            doc.Label,
 		OwnerTag:         doc.OwnerTag,
+		Ephemeral:        doc.Ephemeral,
 		CreateTime:       doc.CreateTime,
 		UpdateTime:       doc.UpdateTime,
 	}, nil
@@ -482,6 +489,26 @@ func (s *secretsStore) DeleteSecret(uri *secrets.URI, revisions ...int) (removed
 	return s.st.deleteSecrets([]*secrets.URI{uri}, revisions...)
 }
 
+// removeOwnerEphemeralSecrets deletes all ephemeral secrets owned by owner.
+// It is used to clean up secrets scoped to a unit's lifetime once that
+// unit has been removed.
+func (st *State) removeOwnerEphemeralSecrets(owner names.Tag) error {
+	store := NewSecrets(st)
+	all, err := store.ListSecrets(SecretsFilter{OwnerTags: []names.Tag{owner}})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, md := range all {
+		if !md.Ephemeral {
+			continue
+		}
+		if _, err := store.DeleteSecret(md.URI); err != nil {
+			return errors.Annotatef(err, "deleting ephemeral secret %q", md.URI)
+		}
+	}
+	return nil
+}
+
 func (st *State) deleteSecrets(uris []*secrets.URI, revisions ...int) (removed bool, err error) {
 	// We will bulk delete the various artefacts, starting with the secret itself.
 	// Deleting the parent secret metadata first will ensure that any consumers of
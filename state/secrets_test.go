@@ -256,6 +256,41 @@ func (s *SecretsSuite) TestCreateDyingOwner(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `cannot create secret for owner "application-mysql" which is not alive`)
 }
 
+func (s *SecretsSuite) TestRemovedUnitPrunesEphemeralSecrets(c *gc.C) {
+	ephemeralURI := secrets.NewURI()
+	_, err := s.store.CreateSecret(ephemeralURI, state.CreateSecretParams{
+		Version:   1,
+		Owner:     s.ownerUnit.Tag(),
+		Ephemeral: true,
+		UpdateSecretParams: state.UpdateSecretParams{
+			LeaderToken: &fakeToken{},
+			Data:        map[string]string{"foo": "bar"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	keptURI := secrets.NewURI()
+	_, err = s.store.CreateSecret(keptURI, state.CreateSecretParams{
+		Version: 1,
+		Owner:   s.ownerUnit.Tag(),
+		UpdateSecretParams: state.UpdateSecretParams{
+			LeaderToken: &fakeToken{},
+			Data:        map[string]string{"foo": "bar"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.ownerUnit.EnsureDead(), jc.ErrorIsNil)
+	c.Assert(s.ownerUnit.Remove(), jc.ErrorIsNil)
+	c.Assert(s.State.Cleanup(), jc.ErrorIsNil)
+
+	_, err = s.store.GetSecret(ephemeralURI)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	_, err = s.store.GetSecret(keptURI)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 func (s *SecretsSuite) TestGetValueNotFound(c *gc.C) {
 	uri, _ := secrets.ParseURI("secret:9m4e2mr0ui3e8a215n4g")
 	_, _, err := s.store.GetSecretValue(uri, 666)
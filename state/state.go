@@ -688,6 +688,27 @@ func (st *State) AllMachines() ([]*Machine, error) {
 	return st.allMachines(machinesCollection)
 }
 
+// MachineLiveness returns, for every machine in the model, whether its
+// agent currently believes itself to be alive. It enumerates AllMachines
+// and checks each one's cached agent status in a single pass, so unlike
+// a per-machine watcher it never blocks waiting for a presence change -
+// it only reports the belief as of the time it was called.
+func (st *State) MachineLiveness() (map[string]bool, error) {
+	machines, err := st.AllMachines()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	liveness := make(map[string]bool, len(machines))
+	for _, m := range machines {
+		statusInfo, err := m.Status()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		liveness[m.Id()] = statusInfo.Status == status.Started
+	}
+	return liveness, nil
+}
+
 // MachineCountForBase counts the machines for the provided bases in the model.
 // The bases must all be for the one os.
 func (st *State) MachineCountForBase(base ...Base) (map[string]int, error) {
@@ -772,7 +793,11 @@ func machineIdLessThan(id1, id2 string) bool {
 	return nrParts1 < nrParts2
 }
 
-// Machine returns the machine with the given id.
+// Machine returns the machine with the given id. Machine ids in this
+// package are opaque slash-separated strings (e.g. "3" or "3/lxd/2" for a
+// container), not bare integers, so unlike some other lookups there is no
+// separate numeric-parsing step: any id that doesn't match a document,
+// well-formed or not, comes back as an error satisfying errors.IsNotFound.
 func (st *State) Machine(id string) (*Machine, error) {
 	mdoc, err := st.getMachineDoc(id)
 	if err != nil {
@@ -2560,6 +2585,29 @@ func (st *State) networkEntityGlobalKey(globalKey string, providerId corenetwork
 	return st.docID(globalKey + ":" + string(providerId))
 }
 
+// runWithRetry runs the transaction returned by ops, calling ops again to
+// rebuild it and retrying up to attempts times if the previous attempt
+// was aborted by a concurrent change. Unlike db().Run, which takes a
+// jujutxn.TransactionSource and already retries internally, this is for
+// callers that build and run a single txn.Op slice directly via
+// db().RunTransaction and would otherwise lose the result of a
+// transient abort instead of retrying against fresh state.
+func (st *State) runWithRetry(ops func() ([]txn.Op, error), attempts int) error {
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		var txnOps []txn.Op
+		txnOps, err = ops()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		err = st.db().RunTransaction(txnOps)
+		if err != txn.ErrAborted {
+			return err
+		}
+	}
+	return err
+}
+
 // SetSLA sets the SLA on the current connected model.
 func (st *State) SetSLA(level, owner string, credentials []byte) error {
 	model, err := st.Model()
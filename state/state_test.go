@@ -122,6 +122,41 @@ func (s *StateSuite) TestIsController(c *gc.C) {
 	c.Assert(st2.IsController(), jc.IsFalse)
 }
 
+func (s *StateSuite) TestRunWithRetrySucceedsAfterTransientAbort(c *gc.C) {
+	settingsKey := "run-with-retry-test"
+	settings := s.State.NewSettings()
+	err := settings.CreateSettings(settingsKey, map[string]interface{}{"answer": 41})
+	c.Assert(err, jc.ErrorIsNil)
+
+	attempt := 0
+	ops := func() ([]mgotxn.Op, error) {
+		attempt++
+		// On the first attempt, assert against a value that doesn't
+		// match the document, simulating a transaction that lost a
+		// race against a concurrent change and was aborted. From the
+		// second attempt onwards, assert against the real value so
+		// the transaction can succeed.
+		assert := bson.D{{"settings.answer", 999}}
+		if attempt > 1 {
+			assert = bson.D{{"settings.answer", 41}}
+		}
+		return []mgotxn.Op{{
+			C:      state.SettingsC,
+			Id:     state.DocID(s.State, settingsKey),
+			Assert: assert,
+			Update: bson.D{{"$set", bson.D{{"settings.answer", 42}}}},
+		}}, nil
+	}
+
+	err = s.State.RunWithRetryForTest(ops, 3)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(attempt, gc.Equals, 2)
+
+	updated, err := settings.ReadSettings(settingsKey)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(updated["answer"], gc.Equals, 42)
+}
+
 func (s *StateSuite) TestControllerOwner(c *gc.C) {
 	owner, err := s.State.ControllerOwner()
 	c.Assert(err, jc.ErrorIsNil)
@@ -1411,6 +1446,12 @@ func (s *StateSuite) TestMachineNotFound(c *gc.C) {
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 }
 
+func (s *StateSuite) TestMachineMalformedId(c *gc.C) {
+	_, err := s.State.Machine("not-a-valid-id")
+	c.Assert(err, gc.ErrorMatches, "machine not-a-valid-id not found")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *StateSuite) TestMachineIdLessThan(c *gc.C) {
 	c.Assert(state.MachineIdLessThan("0", "0"), jc.IsFalse)
 	c.Assert(state.MachineIdLessThan("0", "1"), jc.IsTrue)
@@ -1424,6 +1465,31 @@ func (s *StateSuite) TestMachineIdLessThan(c *gc.C) {
 	c.Assert(state.MachineIdLessThan("0/kvm/0", "0/lxd/0"), jc.IsTrue)
 }
 
+func (s *StateSuite) TestAllMachinesSortedByIdRegardlessOfInsertOrder(c *gc.C) {
+	// Insert the machine documents directly and out of numeric order, to
+	// exercise AllMachines' sort rather than the incidental ordering
+	// AddMachine would otherwise produce.
+	for _, id := range []string{"2", "0", "1"} {
+		state.RunTransaction(c, s.State, []mgotxn.Op{{
+			C:      state.MachinesC,
+			Id:     state.DocID(s.State, id),
+			Assert: mgotxn.DocMissing,
+			Insert: bson.M{
+				"machineid":  id,
+				"model-uuid": s.State.ModelUUID(),
+				"life":       state.Alive,
+			},
+		}})
+	}
+
+	ms, err := s.State.AllMachines()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ms, gc.HasLen, 3)
+	for i, m := range ms {
+		c.Assert(m.Id(), gc.Equals, strconv.Itoa(i))
+	}
+}
+
 func (s *StateSuite) TestAllMachines(c *gc.C) {
 	numInserts := 42
 	for i := 0; i < numInserts; i++ {
@@ -1450,6 +1516,23 @@ func (s *StateSuite) TestAllMachines(c *gc.C) {
 	}
 }
 
+func (s *StateSuite) TestMachineLiveness(c *gc.C) {
+	alive, err := s.State.AddMachine(state.UbuntuBase("12.10"), state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = alive.SetStatus(status.StatusInfo{Status: status.Started})
+	c.Assert(err, jc.ErrorIsNil)
+
+	dead, err := s.State.AddMachine(state.UbuntuBase("12.10"), state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	liveness, err := s.State.MachineLiveness()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(liveness, jc.DeepEquals, map[string]bool{
+		alive.Id(): true,
+		dead.Id():  false,
+	})
+}
+
 func (s *StateSuite) TestMachineCountForBase(c *gc.C) {
 	add_machine := func(base state.Base) {
 		m, err := s.State.AddMachine(base, state.JobHostUnits)
@@ -4,6 +4,8 @@
 package state_test
 
 import (
+	"fmt"
+
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -133,14 +135,18 @@ func (s *MachineStatusSuite) TestGetSetStatusDying(c *gc.C) {
 	s.checkGetSetStatus(c)
 }
 
-func (s *MachineStatusSuite) TestGetSetStatusDead(c *gc.C) {
+func (s *MachineStatusSuite) TestSetStatusRejectedWhenDead(c *gc.C) {
 	err := s.machine.EnsureDead()
 	c.Assert(err, jc.ErrorIsNil)
 
-	// NOTE: it would be more technically correct to reject status updates
-	// while Dead, but it's easier and clearer, not to mention more efficient,
-	// to just depend on status doc existence.
-	s.checkGetSetStatus(c)
+	now := testing.ZeroTime()
+	sInfo := status.StatusInfo{
+		Status:  status.Started,
+		Message: "blah",
+		Since:   &now,
+	}
+	err = s.machine.SetStatus(sInfo)
+	c.Check(err, gc.ErrorMatches, fmt.Sprintf(`cannot set status of dead machine %q`, s.machine.Id()))
 }
 
 func (s *MachineStatusSuite) TestGetSetStatusGone(c *gc.C) {
@@ -67,6 +67,15 @@ type StringsWatcher interface {
 	Changes() <-chan []string
 }
 
+// LifeWatcher generates a signal carrying an entity's current Life, once
+// immediately and then again every time it changes. It never delivers the
+// same value twice in a row, so callers don't need to Refresh and compare
+// Life themselves as they would with a NotifyWatcher.
+type LifeWatcher interface {
+	Watcher
+	Changes() <-chan Life
+}
+
 // RelationUnitsWatcher generates signals when units enter or leave
 // the scope of a RelationUnit, and changes to the settings of those
 // units known to have entered.
@@ -1961,6 +1970,14 @@ func (m *Machine) WatchInstanceData() NotifyWatcher {
 	return newEntityWatcher(m.st, instanceDataC, m.doc.DocID)
 }
 
+// WatchInstanceId returns a watcher for observing changes to a machine's
+// instance id, most notably the transition from unprovisioned to
+// provisioned when SetProvisioned records the instance id. As with other
+// entity watchers, the first event fires with the current state.
+func (m *Machine) WatchInstanceId() NotifyWatcher {
+	return m.WatchInstanceData()
+}
+
 // WatchControllerInfo returns a StringsWatcher for the controllers collection
 func (st *State) WatchControllerInfo() StringsWatcher {
 	return newCollectionWatcher(st, colWCfg{col: controllerNodesC})
@@ -1981,6 +1998,18 @@ func (m *Machine) Watch() NotifyWatcher {
 	return newEntityWatcher(m.st, machinesC, m.doc.DocID)
 }
 
+// WatchLife returns a watcher that emits m's Life, once immediately and
+// then again on each Alive->Dying->Dead transition, so that callers no
+// longer need to Watch, Refresh and compare Life themselves.
+func (m *Machine) WatchLife() LifeWatcher {
+	return newLifeWatcher(m.st, machinesC, m.doc.DocID, func() (Life, error) {
+		if err := m.Refresh(); err != nil {
+			return Dead, errors.Trace(err)
+		}
+		return m.Life(), nil
+	})
+}
+
 // Watch returns a watcher for observing changes to an application.
 func (a *Application) Watch() NotifyWatcher {
 	return newEntityWatcher(a.st, applicationsC, a.doc.DocID)
@@ -2344,6 +2373,68 @@ func newEntityWatcher(backend modelBackend, collName string, key interface{}) No
 	return newDocWatcher(backend, []docKey{{collName, key}})
 }
 
+// lifeWatcher implements LifeWatcher on top of the same txn-log watching
+// machinery as docWatcher, re-deriving the observed Life via getLife
+// whenever the underlying document changes.
+type lifeWatcher struct {
+	commonWatcher
+	out     chan Life
+	getLife func() (Life, error)
+}
+
+var _ Watcher = (*lifeWatcher)(nil)
+
+// newLifeWatcher returns a LifeWatcher for the document identified by
+// collName and id, using getLife to derive the current Life whenever that
+// document changes.
+func newLifeWatcher(backend modelBackend, collName string, id interface{}, getLife func() (Life, error)) LifeWatcher {
+	w := &lifeWatcher{
+		commonWatcher: newCommonWatcher(backend),
+		out:           make(chan Life),
+		getLife:       getLife,
+	}
+	w.tomb.Go(func() error {
+		defer close(w.out)
+		return w.loop(collName, id)
+	})
+	return w
+}
+
+// Changes returns the event channel for the lifeWatcher.
+func (w *lifeWatcher) Changes() <-chan Life {
+	return w.out
+}
+
+func (w *lifeWatcher) loop(collName string, id interface{}) error {
+	in := make(chan watcher.Change)
+	w.watcher.Watch(collName, id, in)
+	defer w.watcher.Unwatch(collName, id, in)
+
+	life, err := w.getLife()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-in:
+			newLife, err := w.getLife()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if newLife == life {
+				continue
+			}
+			life = newLife
+			out = w.out
+		case out <- life:
+			out = nil
+		}
+	}
+}
+
 // docWatcher watches for changes in 1 or more mongo documents
 // across collections.
 type docWatcher struct {
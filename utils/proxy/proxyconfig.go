@@ -121,6 +121,20 @@ func (pc *ProxyConfig) useProxy(addr string) bool {
 	return true
 }
 
+// ShouldBypassProxy reports whether host should bypass the proxy according
+// to the configured NoProxy rules (exact host, wildcard domain suffix, CIDR,
+// and port-specific entries). It lets callers that don't have an
+// *http.Request to hand, such as jujud deciding whether to proxy a
+// controller-internal address, reuse the same NoProxy logic as GetProxy.
+func (pc *ProxyConfig) ShouldBypassProxy(host string) bool {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if !hasPort(host) {
+		host = net.JoinHostPort(host, "0")
+	}
+	return !pc.useProxy(host)
+}
+
 // InstallInDefaultTransport sets the proxy resolution used by the
 // default HTTP transport to use the proxy details stored in this
 // ProxyConfig. Requests made without an explicit transport will
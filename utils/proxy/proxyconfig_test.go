@@ -48,6 +48,31 @@ var (
 	}
 )
 
+func (s *Suite) TestShouldBypassProxy(c *gc.C) {
+	pc := proxyconfig.ProxyConfig{}
+	c.Assert(pc.Set(normal), jc.ErrorIsNil)
+
+	// Exact host entry.
+	c.Check(pc.ShouldBypassProxy("bar.com"), jc.IsTrue)
+	// Wildcard domain suffix entry.
+	c.Check(pc.ShouldBypassProxy("adz.foo.com"), jc.IsTrue)
+	c.Check(pc.ShouldBypassProxy("foo.com"), jc.IsTrue)
+	// CIDR entry.
+	c.Check(pc.ShouldBypassProxy("192.168.1.42"), jc.IsTrue)
+	// Port-specific entry, matched regardless of the port passed in since
+	// the host portion alone is compared.
+	c.Check(pc.ShouldBypassProxy("10.0.0.1:3333"), jc.IsTrue)
+	c.Check(pc.ShouldBypassProxy("10.0.0.1"), jc.IsTrue)
+	// Not in any NoProxy rule.
+	c.Check(pc.ShouldBypassProxy("decemberists.com"), jc.IsFalse)
+}
+
+func (s *Suite) TestShouldBypassProxyWildcard(c *gc.C) {
+	pc := proxyconfig.ProxyConfig{}
+	c.Assert(pc.Set(noProxy), jc.ErrorIsNil)
+	c.Check(pc.ShouldBypassProxy("anything.at.all"), jc.IsTrue)
+}
+
 func (s *Suite) TestGetProxy(c *gc.C) {
 	checkProxy(c, normal, "https://perfect.crime", "https://https.proxy")
 	checkProxy(c, normal, "http://decemberists.com", "http://http.proxy")
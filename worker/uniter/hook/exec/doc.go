@@ -0,0 +1,6 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package exec runs a single charm hook as an external process,
+// assembling the environment hook tools expect to find.
+package exec
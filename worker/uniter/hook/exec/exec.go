@@ -0,0 +1,386 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package exec
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/juju/osenv"
+)
+
+// ErrHookTimeout is returned by Exec when the hook process is killed
+// because it ran for longer than ExecInfo.Timeout.
+var ErrHookTimeout = errors.New("hook execution timed out")
+
+// HookError wraps a hook process's non-zero exit, exposing its exit code
+// directly so that callers can decide policy (retry vs. fail) based on
+// exactly how the hook failed, rather than type-asserting down to
+// *exec.ExitError themselves.
+type HookError struct {
+	// Err is the underlying exit error from the hook process.
+	Err *osexec.ExitError
+}
+
+// Error is part of the error interface.
+func (e *HookError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap allows errors.Is and errors.As to see through to Err.
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the hook process's exit code.
+func (e *HookError) ExitCode() int {
+	return e.Err.ExitCode()
+}
+
+// ExecInfo holds the parameters required to run a single hook.
+type ExecInfo struct {
+	// Path is the path to the hook executable.
+	Path string
+
+	// Args are any additional arguments to pass to the hook.
+	Args []string
+
+	// CharmDir is the charm's unpacked directory. It is the hook's
+	// working directory unless WorkingDir overrides it, and is always
+	// exposed to the hook as CHARM_DIR.
+	CharmDir string
+
+	// WorkingDir, if set, overrides CharmDir as the hook's working
+	// directory - for example when a hook needs to run from a storage
+	// mount. CHARM_DIR in the hook's environment still points at
+	// CharmDir regardless. It must exist, or Exec returns an error.
+	WorkingDir string
+
+	// ContextId identifies the hook context that hook tools invoked
+	// by the hook should connect back to.
+	ContextId string
+
+	// AgentSocket is the address of the unit agent socket that hook
+	// tools should use to reach the agent. Deprecated in favour of
+	// AgentSocketAddress, but still set for backwards compatibility.
+	AgentSocket string
+
+	// AgentSocketAddress is the modern address of the unit agent
+	// socket, which may be a unix socket path or an abstract socket
+	// name depending on SocketNetwork.
+	AgentSocketAddress string
+
+	// SocketNetwork is the network of AgentSocketAddress, either
+	// "unix" or "tcp".
+	SocketNetwork string
+
+	// RemoteUnit is the related unit, if any, that triggered the hook.
+	RemoteUnit string
+
+	// MachineId is the id of the machine the hook is running on.
+	MachineId string
+
+	// UnitName is the name of the unit the hook is running for.
+	UnitName string
+
+	// AvailabilityZone is the cloud availability zone the unit's machine
+	// is running in, if known.
+	AvailabilityZone string
+
+	// Timeout bounds how long the hook may run before it is killed.
+	// A zero value means unlimited.
+	Timeout time.Duration
+
+	// Stdout and Stderr receive the hook process's output. If nil,
+	// they default to os.Stdout and os.Stderr respectively.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// ExtraVars holds additional environment variables to set for the
+	// hook, appended after the built-in ones so they can override them.
+	ExtraVars map[string]string
+
+	// Umask, if set, is applied as the process umask for the moment the
+	// hook process is started, so that files it creates get consistent
+	// permissions regardless of jujud's own umask. It is restored
+	// immediately afterwards. It is ignored on Windows, which has no
+	// umask concept.
+	Umask *int
+}
+
+// Vars returns the environment variables that should be set when
+// running the hook described by info.
+func (info ExecInfo) Vars() []string {
+	vars := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"CHARM_DIR=" + info.CharmDir,
+		"JUJU_CONTEXT_ID=" + info.ContextId,
+		"JUJU_AGENT_SOCKET=" + info.AgentSocket,
+	}
+	if info.RemoteUnit != "" {
+		vars = append(vars, "JUJU_REMOTE_UNIT="+info.RemoteUnit)
+	}
+	if info.AgentSocketAddress != "" {
+		vars = append(vars, "JUJU_AGENT_SOCKET_ADDRESS="+info.AgentSocketAddress)
+		vars = append(vars, "JUJU_AGENT_SOCKET_NETWORK="+info.SocketNetwork)
+	}
+	if info.MachineId != "" {
+		vars = append(vars, "JUJU_MACHINE_ID="+info.MachineId)
+	}
+	if info.UnitName != "" {
+		vars = append(vars, "JUJU_UNIT_NAME="+info.UnitName)
+	}
+	if info.AvailabilityZone != "" {
+		vars = append(vars, "JUJU_AVAILABILITY_ZONE="+info.AvailabilityZone)
+	}
+	extras := osenv.MergeEnvironment(nil, info.ExtraVars)
+	keys := make([]string, 0, len(extras))
+	for k := range extras {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		vars = append(vars, k+"="+extras[k])
+	}
+	return vars
+}
+
+// DryRunResult describes what Exec would have done, without actually
+// running anything.
+type DryRunResult struct {
+	// Exists is true if info.Path exists and would have been run. If
+	// false, Path and Env are still populated, but Exec would have
+	// silently skipped the hook.
+	Exists bool
+
+	// Path is the fully-resolved path to the hook executable.
+	Path string
+
+	// Args are the arguments Exec would have passed to the hook.
+	Args []string
+
+	// Env is the complete, merged environment Exec would have set for
+	// the hook process.
+	Env []string
+
+	// WorkingDir is the directory Exec would have run the hook in.
+	WorkingDir string
+}
+
+// ExecDryRun reports what Exec would do for info, without running the
+// hook. It distinguishes a hook that does not exist on disk (Exists
+// false, not an error) from a runnable hook (Exists true).
+func ExecDryRun(info ExecInfo) (DryRunResult, error) {
+	result := DryRunResult{
+		Path:       info.Path,
+		Args:       info.Args,
+		Env:        append(info.Vars(), "JUJU_HOOK_NAME="+filepath.Base(info.Path)),
+		WorkingDir: info.workingDir(),
+	}
+	fi, err := os.Stat(info.Path)
+	if err != nil {
+		if isImportant(err) {
+			return DryRunResult{}, errors.Trace(err)
+		}
+		return result, nil
+	}
+	result.Exists = true
+	if fi.Mode()&0111 == 0 {
+		if path, args, ok := viaInterpreter(info.Path, info.Args); ok {
+			result.Path, result.Args = path, args
+		}
+	}
+	return result, nil
+}
+
+// needsInterpreter reports whether err, returned from trying to start a
+// process directly, indicates that the kernel couldn't execute the file
+// itself - either because it lacks the execute bit, or because its
+// shebang names an interpreter the kernel can't honour (as can happen
+// in some container runtimes). In either case, re-invoking via the
+// interpreter named on the file's shebang line may still work.
+func needsInterpreter(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.ENOEXEC)
+}
+
+// shebangInterpreter reads the first line of path and, if it is a
+// shebang ("#!interpreter [arg]"), returns the interpreter and its
+// optional single argument. It returns ok=false if the file has no
+// shebang line.
+func shebangInterpreter(path string) (interpreter string, arg string, ok bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", false, errors.Trace(err)
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", "", false, errors.Trace(err)
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "#!") {
+		return "", "", false, nil
+	}
+	fields := strings.SplitN(strings.TrimSpace(line[2:]), " ", 2)
+	interpreter = strings.TrimSpace(fields[0])
+	if interpreter == "" {
+		return "", "", false, nil
+	}
+	if len(fields) == 2 {
+		arg = strings.TrimSpace(fields[1])
+	}
+	return interpreter, arg, true, nil
+}
+
+// viaInterpreter returns the path and arguments that would invoke the
+// hook at path through the interpreter named on its shebang line,
+// ahead of its own arguments. ok is false if path has no shebang line,
+// in which case path and args are returned unchanged.
+func viaInterpreter(path string, args []string) (newPath string, newArgs []string, ok bool) {
+	interpreter, arg, found, err := shebangInterpreter(path)
+	if err != nil || !found {
+		return path, args, false
+	}
+	interpArgs := []string{}
+	if arg != "" {
+		interpArgs = append(interpArgs, arg)
+	}
+	interpArgs = append(interpArgs, path)
+	interpArgs = append(interpArgs, args...)
+	return interpreter, interpArgs, true
+}
+
+// commandFor returns the path and arguments that should be used to
+// invoke the hook at path, falling back to its shebang interpreter
+// when runErr indicates the kernel couldn't execute the file directly.
+// If no fallback applies, it returns path and args unchanged.
+func commandFor(path string, args []string, runErr error) (string, []string) {
+	if !needsInterpreter(runErr) {
+		return path, args
+	}
+	newPath, newArgs, _ := viaInterpreter(path, args)
+	return newPath, newArgs
+}
+
+// isImportant reports whether err, returned from starting or running
+// a hook process, represents a real failure as opposed to the hook
+// simply not existing on disk (which is not an error: most hooks are
+// optional).
+func isImportant(err error) bool {
+	return err != nil && !os.IsNotExist(err)
+}
+
+// workingDir returns the directory the hook should run in: WorkingDir
+// if set, otherwise CharmDir.
+func (info ExecInfo) workingDir() string {
+	if info.WorkingDir != "" {
+		return info.WorkingDir
+	}
+	return info.CharmDir
+}
+
+// Exec runs the hook described by info to completion. A hook that does
+// not exist is silently treated as a no-op. If info.Timeout is
+// positive and the hook does not finish in time, the process is
+// killed and ErrHookTimeout is returned. If the hook runs to completion
+// but exits non-zero, a *HookError is returned so the caller can inspect
+// ExitCode(). If info.WorkingDir is set but does not exist, Exec returns
+// an error without running the hook. It is equivalent to
+// ExecWithContext(context.Background(), info).
+func Exec(info ExecInfo) error {
+	return ExecWithContext(context.Background(), info)
+}
+
+// ExecWithContext is Exec, but also killed if ctx is cancelled or its
+// deadline expires, so that callers that already carry a context for
+// the overall operation can have it cancel any hook still running. A
+// cancellation of ctx itself is returned as ctx.Err(); running out of
+// info.Timeout still returns ErrHookTimeout.
+func ExecWithContext(ctx context.Context, info ExecInfo) error {
+	if _, err := os.Stat(info.Path); err != nil {
+		if isImportant(err) {
+			return errors.Trace(err)
+		}
+		return nil
+	}
+
+	dir := info.workingDir()
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return errors.Errorf("working directory %q does not exist", dir)
+	}
+
+	runCtx := ctx
+	cancel := func() {}
+	if info.Timeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, info.Timeout)
+	}
+	defer cancel()
+
+	// JUJU_HOOK_NAME identifies the hook currently executing, for the
+	// benefit of tools invoked by it. It names the hook being run, not
+	// whatever interpreter run may end up invoking it through, so it is
+	// computed once here rather than inside run.
+	hookName := filepath.Base(info.Path)
+
+	run := func(path string, args []string) error {
+		cmd := osexec.CommandContext(runCtx, path, args...)
+		cmd.Dir = dir
+		cmd.Env = append(info.Vars(), "JUJU_HOOK_NAME="+hookName)
+		cmd.Stdout = info.Stdout
+		if cmd.Stdout == nil {
+			cmd.Stdout = os.Stdout
+		}
+		cmd.Stderr = info.Stderr
+		if cmd.Stderr == nil {
+			cmd.Stderr = os.Stderr
+		}
+		if info.Umask == nil {
+			return cmd.Run()
+		}
+		restore := setUmask(*info.Umask)
+		err := cmd.Start()
+		restore()
+		if err != nil {
+			return err
+		}
+		return cmd.Wait()
+	}
+
+	err := run(info.Path, info.Args)
+	if needsInterpreter(err) {
+		if path, args := commandFor(info.Path, info.Args, err); path != info.Path {
+			err = run(path, args)
+		}
+	}
+	// Check the caller's own ctx first: if it is what ended the run, report
+	// that rather than attributing it to info.Timeout, even though both
+	// are ultimately the same deadline-derived error from the stdlib once
+	// info.Timeout is also set.
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if runCtx.Err() == context.DeadlineExceeded {
+		return ErrHookTimeout
+	}
+	if !isImportant(err) {
+		return nil
+	}
+	var exitErr *osexec.ExitError
+	if errors.As(err, &exitErr) {
+		return &HookError{Err: exitErr}
+	}
+	return errors.Trace(err)
+}
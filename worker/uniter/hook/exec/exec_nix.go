@@ -0,0 +1,35 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+//go:build !windows
+// +build !windows
+
+package exec
+
+import (
+	"sync"
+	"syscall"
+)
+
+// umaskMutex serializes the save/set/restore sequence below, since
+// syscall.Umask changes process-wide state: without it, two hooks whose
+// Exec/ExecWithContext calls race could each save the other's freshly-set
+// umask as "old", leaving the process with a corrupted umask or applying
+// the wrong umask to a hook's files.
+var umaskMutex sync.Mutex
+
+// setUmask sets the process umask to mask and returns a function that
+// restores the previous umask. The umask only needs to be in effect for
+// the moment the child process forks, so callers should restore it as
+// soon as the child has started rather than holding it for the lifetime
+// of the hook. setUmask itself returns once the new umask is set; the
+// mutex is held until the returned function is called, so callers must
+// call it promptly.
+func setUmask(mask int) func() {
+	umaskMutex.Lock()
+	old := syscall.Umask(mask)
+	return func() {
+		syscall.Umask(old)
+		umaskMutex.Unlock()
+	}
+}
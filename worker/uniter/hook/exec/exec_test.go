@@ -0,0 +1,354 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package exec_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/hook/exec"
+)
+
+type ExecSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&ExecSuite{})
+
+func writeScript(c *gc.C, dir, name, contents string) string {
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, []byte(contents), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	return path
+}
+
+func (s *ExecSuite) TestExecMissingHookIsNotError(c *gc.C) {
+	dir := c.MkDir()
+	err := exec.Exec(exec.ExecInfo{Path: filepath.Join(dir, "missing-hook")})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ExecSuite) TestExecRunsHook(c *gc.C) {
+	dir := c.MkDir()
+	marker := filepath.Join(dir, "ran")
+	path := writeScript(c, dir, "config-changed", "#!/bin/sh\ntouch "+marker+"\n")
+
+	err := exec.Exec(exec.ExecInfo{Path: path, CharmDir: dir})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = os.Stat(marker)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ExecSuite) TestExecHookExitsZeroIsNotError(c *gc.C) {
+	dir := c.MkDir()
+	path := writeScript(c, dir, "config-changed", "#!/bin/sh\nexit 0\n")
+
+	err := exec.Exec(exec.ExecInfo{Path: path, CharmDir: dir})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ExecSuite) TestExecHookExitsNonZeroReturnsHookError(c *gc.C) {
+	dir := c.MkDir()
+	path := writeScript(c, dir, "config-changed", "#!/bin/sh\nexit 3\n")
+
+	err := exec.Exec(exec.ExecInfo{Path: path, CharmDir: dir})
+	var hookErr *exec.HookError
+	c.Assert(errors.As(err, &hookErr), jc.IsTrue)
+	c.Assert(hookErr.ExitCode(), gc.Equals, 3)
+
+	var exitErr *osexec.ExitError
+	c.Assert(errors.As(err, &exitErr), jc.IsTrue)
+}
+
+func (s *ExecSuite) TestExecMissingHookDoesNotReturnHookError(c *gc.C) {
+	dir := c.MkDir()
+	err := exec.Exec(exec.ExecInfo{Path: filepath.Join(dir, "missing-hook"), CharmDir: dir})
+	c.Assert(err, jc.ErrorIsNil)
+
+	var hookErr *exec.HookError
+	c.Assert(errors.As(err, &hookErr), jc.IsFalse)
+}
+
+func (s *ExecSuite) TestExecDryRunMissingHook(c *gc.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "hooks", "config-changed")
+
+	result, err := exec.ExecDryRun(exec.ExecInfo{Path: path, CharmDir: dir})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Exists, jc.IsFalse)
+	c.Assert(result.Path, gc.Equals, path)
+}
+
+func (s *ExecSuite) TestExecDryRunResolvesPathAndEnv(c *gc.C) {
+	dir := c.MkDir()
+	hooksDir := filepath.Join(dir, "hooks")
+	c.Assert(os.MkdirAll(hooksDir, 0755), jc.ErrorIsNil)
+	path := writeScript(c, hooksDir, "config-changed", "#!/bin/sh\ntouch "+filepath.Join(dir, "ran")+"\n")
+
+	info := exec.ExecInfo{
+		Path:      path,
+		CharmDir:  dir,
+		ContextId: "ctx-0",
+		UnitName:  "mysql/0",
+	}
+	result, err := exec.ExecDryRun(info)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Exists, jc.IsTrue)
+	c.Assert(result.Path, gc.Equals, filepath.Join(dir, "hooks", "config-changed"))
+	c.Assert(result.Env, jc.DeepEquals, append(info.Vars(), "JUJU_HOOK_NAME=config-changed"))
+
+	// ExecDryRun must not actually have run anything.
+	_, err = os.Stat(filepath.Join(dir, "ran"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *ExecSuite) TestExecDryRunEnvIncludesJujuHookName(c *gc.C) {
+	dir := c.MkDir()
+	path := writeScript(c, dir, "config-changed", "#!/bin/sh\n")
+
+	info := exec.ExecInfo{Path: path, CharmDir: dir}
+	result, err := exec.ExecDryRun(info)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// A dry run is meant to preview exactly what Exec would do, so its
+	// environment must include JUJU_HOOK_NAME just as a real run's does.
+	c.Assert(result.Env, jc.DeepEquals, append(info.Vars(), "JUJU_HOOK_NAME=config-changed"))
+}
+
+func (s *ExecSuite) TestExecNonExecutableHookUsesShebangInterpreter(c *gc.C) {
+	python3, err := osexec.LookPath("python3")
+	if err != nil {
+		c.Skip("python3 not available")
+	}
+
+	dir := c.MkDir()
+	marker := filepath.Join(dir, "ran")
+	path := filepath.Join(dir, "config-changed")
+	script := "#!" + python3 + "\nopen(" + `"` + marker + `"` + ", 'w').close()\n"
+	c.Assert(os.WriteFile(path, []byte(script), 0644), jc.ErrorIsNil)
+
+	err = exec.Exec(exec.ExecInfo{Path: path, CharmDir: dir})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = os.Stat(marker)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ExecSuite) TestExecMissingHookStillNotError(c *gc.C) {
+	dir := c.MkDir()
+	err := exec.Exec(exec.ExecInfo{Path: filepath.Join(dir, "hooks", "config-changed"), CharmDir: dir})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ExecSuite) TestExecDryRunWorkingDir(c *gc.C) {
+	charmDir := c.MkDir()
+	path := writeScript(c, charmDir, "config-changed", "#!/bin/sh\n")
+
+	result, err := exec.ExecDryRun(exec.ExecInfo{Path: path, CharmDir: charmDir})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.WorkingDir, gc.Equals, charmDir)
+
+	workingDir := c.MkDir()
+	result, err = exec.ExecDryRun(exec.ExecInfo{Path: path, CharmDir: charmDir, WorkingDir: workingDir})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.WorkingDir, gc.Equals, workingDir)
+}
+
+func (s *ExecSuite) TestExecDryRunNonExecutableHookResolvesInterpreter(c *gc.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "config-changed")
+	c.Assert(os.WriteFile(path, []byte("#!/usr/bin/env python3\n"), 0644), jc.ErrorIsNil)
+
+	result, err := exec.ExecDryRun(exec.ExecInfo{Path: path, CharmDir: dir})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Exists, jc.IsTrue)
+	c.Assert(result.Path, gc.Equals, "/usr/bin/env")
+	c.Assert(result.Args, jc.DeepEquals, []string{"python3", path})
+}
+
+func (s *ExecSuite) TestExecDefaultsToCharmDir(c *gc.C) {
+	dir := c.MkDir()
+	pwdFile := filepath.Join(dir, "pwd")
+	path := writeScript(c, dir, "config-changed", "#!/bin/sh\npwd > "+pwdFile+"\n")
+
+	err := exec.Exec(exec.ExecInfo{Path: path, CharmDir: dir})
+	c.Assert(err, jc.ErrorIsNil)
+
+	got, err := os.ReadFile(pwdFile)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(strings.TrimSpace(string(got)), gc.Equals, dir)
+}
+
+func (s *ExecSuite) TestExecWorkingDirOverridesCharmDir(c *gc.C) {
+	charmDir := c.MkDir()
+	workingDir := c.MkDir()
+	pwdFile := filepath.Join(workingDir, "pwd")
+	charmDirFile := filepath.Join(workingDir, "charmdir")
+	path := writeScript(c, charmDir, "config-changed",
+		"#!/bin/sh\npwd > "+pwdFile+"\necho -n $CHARM_DIR > "+charmDirFile+"\n")
+
+	err := exec.Exec(exec.ExecInfo{Path: path, CharmDir: charmDir, WorkingDir: workingDir})
+	c.Assert(err, jc.ErrorIsNil)
+
+	got, err := os.ReadFile(pwdFile)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(strings.TrimSpace(string(got)), gc.Equals, workingDir)
+
+	gotCharmDir, err := os.ReadFile(charmDirFile)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(gotCharmDir), gc.Equals, charmDir)
+}
+
+func (s *ExecSuite) TestExecWorkingDirMustExist(c *gc.C) {
+	dir := c.MkDir()
+	path := writeScript(c, dir, "config-changed", "#!/bin/sh\n")
+
+	err := exec.Exec(exec.ExecInfo{
+		Path:       path,
+		CharmDir:   dir,
+		WorkingDir: filepath.Join(dir, "missing"),
+	})
+	c.Assert(err, gc.ErrorMatches, `working directory ".*missing" does not exist`)
+}
+
+func (s *ExecSuite) TestExecTimeout(c *gc.C) {
+	dir := c.MkDir()
+	path := writeScript(c, dir, "start", "#!/bin/sh\nsleep 5\n")
+
+	err := exec.Exec(exec.ExecInfo{
+		Path:     path,
+		CharmDir: dir,
+		Timeout:  100 * time.Millisecond,
+	})
+	c.Assert(err, gc.Equals, exec.ErrHookTimeout)
+}
+
+func (s *ExecSuite) TestExecWithContextCancelledMidRun(c *gc.C) {
+	dir := c.MkDir()
+	path := writeScript(c, dir, "start", "#!/bin/sh\nsleep 5\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	err := exec.ExecWithContext(ctx, exec.ExecInfo{Path: path, CharmDir: dir})
+	c.Assert(err, gc.Equals, context.Canceled)
+}
+
+func (s *ExecSuite) TestExecWithContextOwnDeadlineIsNotReportedAsHookTimeout(c *gc.C) {
+	dir := c.MkDir()
+	path := writeScript(c, dir, "start", "#!/bin/sh\nsleep 5\n")
+
+	// The caller's own ctx carries a deadline, but info.Timeout is unset:
+	// the ctx expiring must be reported as ctx.Err(), not ErrHookTimeout,
+	// since it wasn't info.Timeout that ended the run.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := exec.ExecWithContext(ctx, exec.ExecInfo{Path: path, CharmDir: dir})
+	c.Assert(err, gc.Equals, context.DeadlineExceeded)
+	c.Assert(err, gc.Not(gc.Equals), exec.ErrHookTimeout)
+}
+
+func (s *ExecSuite) TestExecUmaskAppliesToCreatedFile(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("umask has no meaning on Windows")
+	}
+	dir := c.MkDir()
+	created := filepath.Join(dir, "created")
+	path := writeScript(c, dir, "config-changed", "#!/bin/sh\ntouch "+created+"\n")
+
+	umask := 0077
+	err := exec.Exec(exec.ExecInfo{Path: path, CharmDir: dir, Umask: &umask})
+	c.Assert(err, jc.ErrorIsNil)
+
+	fi, err := os.Stat(created)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(fi.Mode().Perm(), gc.Equals, os.FileMode(0600))
+}
+
+func (s *ExecSuite) TestExecConcurrentUmasksDoNotRace(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("umask has no meaning on Windows")
+	}
+	dir := c.MkDir()
+
+	run := func(umask int, name string) string {
+		created := filepath.Join(dir, name+"-created")
+		path := writeScript(c, dir, name, "#!/bin/sh\ntouch "+created+"\n")
+		err := exec.Exec(exec.ExecInfo{Path: path, CharmDir: dir, Umask: &umask})
+		c.Check(err, jc.ErrorIsNil)
+		return created
+	}
+
+	var wg sync.WaitGroup
+	var createdA, createdB string
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		createdA = run(0077, "a")
+	}()
+	go func() {
+		defer wg.Done()
+		createdB = run(0007, "b")
+	}()
+	wg.Wait()
+
+	fiA, err := os.Stat(createdA)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(fiA.Mode().Perm(), gc.Equals, os.FileMode(0600))
+
+	fiB, err := os.Stat(createdB)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(fiB.Mode().Perm(), gc.Equals, os.FileMode(0660))
+}
+
+func (s *ExecSuite) TestExecSetsJujuHookNameEnvVar(c *gc.C) {
+	dir := c.MkDir()
+	envFile := filepath.Join(dir, "env")
+	path := writeScript(c, dir, "config-changed", "#!/bin/sh\necho -n $JUJU_HOOK_NAME > "+envFile+"\n")
+
+	info := exec.ExecInfo{Path: path, CharmDir: dir}
+	for _, v := range info.Vars() {
+		c.Assert(v, gc.Not(gc.Matches), "JUJU_HOOK_NAME=.*")
+	}
+
+	err := exec.Exec(info)
+	c.Assert(err, jc.ErrorIsNil)
+
+	got, err := os.ReadFile(envFile)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(got), gc.Equals, "config-changed")
+}
+
+func (s *ExecSuite) TestExecCapturesOutput(c *gc.C) {
+	dir := c.MkDir()
+	path := writeScript(c, dir, "start", "#!/bin/sh\necho out-line\necho err-line >&2\n")
+
+	var stdout, stderr bytes.Buffer
+	err := exec.Exec(exec.ExecInfo{
+		Path:     path,
+		CharmDir: dir,
+		Stdout:   &stdout,
+		Stderr:   &stderr,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stdout.String(), gc.Equals, "out-line\n")
+	c.Assert(stderr.String(), gc.Equals, "err-line\n")
+}
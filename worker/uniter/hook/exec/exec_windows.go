@@ -0,0 +1,10 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package exec
+
+// setUmask is a no-op on Windows, which has no umask concept; ExecInfo.Umask
+// is ignored there.
+func setUmask(mask int) func() {
+	return func() {}
+}
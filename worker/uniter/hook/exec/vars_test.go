@@ -0,0 +1,97 @@
+// Copyright 2023 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package exec_test
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/hook/exec"
+)
+
+type VarsSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&VarsSuite{})
+
+func (s *VarsSuite) TestVarsOmitsEmptyFields(c *gc.C) {
+	vars := exec.ExecInfo{ContextId: "ctx-1", AgentSocket: "/path/to/socket"}.Vars()
+	for _, v := range vars {
+		c.Assert(v, gc.Not(gc.Matches), `JUJU_MACHINE_ID=.*`)
+		c.Assert(v, gc.Not(gc.Matches), `JUJU_UNIT_NAME=.*`)
+		c.Assert(v, gc.Not(gc.Matches), `JUJU_AVAILABILITY_ZONE=.*`)
+	}
+}
+
+func (s *VarsSuite) TestVarsIncludesAvailabilityZone(c *gc.C) {
+	vars := exec.ExecInfo{
+		ContextId:        "ctx-1",
+		AgentSocket:      "/path/to/socket",
+		AvailabilityZone: "zone1",
+	}.Vars()
+	c.Assert(occurrences(vars, "JUJU_AVAILABILITY_ZONE=zone1"), gc.Equals, 1)
+}
+
+func (s *VarsSuite) TestVarsIncludesMachineAndUnit(c *gc.C) {
+	vars := exec.ExecInfo{
+		ContextId:   "ctx-1",
+		AgentSocket: "/path/to/socket",
+		MachineId:   "0",
+		UnitName:    "mysql/0",
+	}.Vars()
+	c.Assert(occurrences(vars, "JUJU_MACHINE_ID=0"), gc.Equals, 1)
+	c.Assert(occurrences(vars, "JUJU_UNIT_NAME=mysql/0"), gc.Equals, 1)
+	c.Assert(vars[0], gc.Matches, `PATH=.*`)
+}
+
+func (s *VarsSuite) TestVarsAgentSocketAddressUnix(c *gc.C) {
+	vars := exec.ExecInfo{
+		AgentSocket:        "/var/lib/juju/agent.socket",
+		AgentSocketAddress: "/var/lib/juju/agent.socket",
+		SocketNetwork:      "unix",
+	}.Vars()
+	c.Assert(occurrences(vars, "JUJU_AGENT_SOCKET=/var/lib/juju/agent.socket"), gc.Equals, 1)
+	c.Assert(occurrences(vars, "JUJU_AGENT_SOCKET_ADDRESS=/var/lib/juju/agent.socket"), gc.Equals, 1)
+	c.Assert(occurrences(vars, "JUJU_AGENT_SOCKET_NETWORK=unix"), gc.Equals, 1)
+}
+
+func (s *VarsSuite) TestVarsAgentSocketAddressAbstract(c *gc.C) {
+	vars := exec.ExecInfo{
+		AgentSocket:        "@/var/lib/juju/agent.socket",
+		AgentSocketAddress: "@/var/lib/juju/agent.socket",
+		SocketNetwork:      "tcp",
+	}.Vars()
+	c.Assert(occurrences(vars, "JUJU_AGENT_SOCKET_ADDRESS=@/var/lib/juju/agent.socket"), gc.Equals, 1)
+	c.Assert(occurrences(vars, "JUJU_AGENT_SOCKET_NETWORK=tcp"), gc.Equals, 1)
+}
+
+func (s *VarsSuite) TestVarsExtraVarsAreIncluded(c *gc.C) {
+	vars := exec.ExecInfo{
+		ContextId:   "ctx-1",
+		AgentSocket: "/path/to/socket",
+		ExtraVars:   map[string]string{"FOO": "bar"},
+	}.Vars()
+	c.Assert(occurrences(vars, "FOO=bar"), gc.Equals, 1)
+}
+
+func (s *VarsSuite) TestVarsExtraVarsOverridePath(c *gc.C) {
+	vars := exec.ExecInfo{
+		ContextId:   "ctx-1",
+		AgentSocket: "/path/to/socket",
+		ExtraVars:   map[string]string{"PATH": "/custom/bin"},
+	}.Vars()
+	c.Assert(occurrences(vars, "PATH=/custom/bin"), gc.Equals, 1)
+	c.Assert(vars[len(vars)-1], gc.Equals, "PATH=/custom/bin")
+}
+
+func occurrences(vars []string, want string) int {
+	n := 0
+	for _, v := range vars {
+		if v == want {
+			n++
+		}
+	}
+	return n
+}
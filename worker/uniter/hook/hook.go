@@ -4,6 +4,12 @@
 package hook
 
 import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/juju/charm/v9/hooks"
 	"github.com/juju/errors"
 	"github.com/juju/names/v4"
@@ -56,10 +62,92 @@ type Info struct {
 
 	// SecretLabel is the secret label to expose to the hook.
 	SecretLabel string `yaml:"secret-label,omitempty"`
+
+	// StartedAt records when the hook began executing. It is absent in
+	// state files written by older uniter versions; MigrateStateFile
+	// fills it in when upgrading one of those files.
+	StartedAt time.Time `yaml:"started-at,omitempty"`
+
+	// Members records, for every relation member this hook delivery
+	// knows about, the most recent settings change version seen for
+	// that member, so the uniter can recover after a crash by comparing
+	// these versions against what it observes live to work out which
+	// members changed while it was down. A member whose version isn't
+	// known yet maps to nil.
+	//
+	// State files written before this field existed only ever recorded
+	// RemoteUnit/ChangeVersion for the single unit that triggered the
+	// hook; StateFile.Read synthesizes a one-entry Members map from
+	// those legacy fields when members-versions is absent, so old and
+	// new state files report a consistent view. StateFile.Write always
+	// serializes both members-versions and the legacy
+	// change-version/remote-unit pair, so a file written by this uniter
+	// can still be read by an older one.
+	Members map[string]*int64 `yaml:"members-versions,omitempty"`
+}
+
+// String returns the canonical name of the hook, for use in log messages.
+// For relation hooks it falls back to using the relation id in place of
+// the relation name, since Info alone doesn't carry the relation name;
+// callers that have it should use FullName instead.
+func (hi Info) String() string {
+	return hi.FullName(strconv.Itoa(hi.RelationId))
+}
+
+// FullName returns the canonical name of the hook, for use in log
+// messages: the bare Kind for unit hooks, or "<relationName>-relation-
+// <event>" for relation hooks, suffixed with " for <remote unit>" when a
+// remote unit triggered it.
+func (hi Info) FullName(relationName string) string {
+	name := string(hi.Kind)
+	if IsRelationHook(hi.Kind) {
+		name = fmt.Sprintf("%s-%s", relationName, hi.Kind)
+	}
+	if hi.RemoteUnit != "" {
+		name = fmt.Sprintf("%s for %s", name, hi.RemoteUnit)
+	}
+	return name
+}
+
+// ParseKind turns a hook file name, as found in a charm's hooks
+// directory, back into a Kind. For the fixed unit hook names (install,
+// start, config-changed, and so on) it returns the Kind directly. For
+// names of the form "<relation>-relation-<event>" it returns the
+// relation hook Kind along with the relation name parsed out of the
+// prefix. Names that match neither form return ok=false.
+func ParseKind(name string) (kind hooks.Kind, relationName string, ok bool) {
+	for _, k := range hooks.UnitHooks() {
+		if name == string(k) {
+			return k, "", true
+		}
+	}
+	for _, k := range hooks.RelationHooks() {
+		suffix := "-" + string(k)
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return k, strings.TrimSuffix(name, suffix), true
+		}
+	}
+	return "", "", false
+}
+
+// IsRelationHook reports whether kind is one of the relation hook kinds
+// (relation-created, relation-joined, relation-changed, relation-departed,
+// or relation-broken). It is defined here, rather than as a method on
+// hooks.Kind, because Kind is a type from the vendored
+// github.com/juju/charm/v9/hooks package that this tree cannot add methods
+// to; it simply delegates to Kind.IsRelation.
+func IsRelationHook(kind hooks.Kind) bool {
+	return kind.IsRelation()
 }
 
 // Validate returns an error if the info is not valid.
 func (hi Info) Validate() error {
+	if !IsRelationHook(hi.Kind) && (hi.RelationId != 0 || hi.RemoteUnit != "") {
+		return errors.Errorf("%q hook does not accept relation id or remote unit", hi.Kind)
+	}
+	if hi.Kind == hooks.RelationBroken && hi.RemoteUnit != "" {
+		return errors.Errorf("%q hook does not accept a remote unit", hi.Kind)
+	}
 	switch hi.Kind {
 	case hooks.RelationChanged:
 		if hi.RemoteUnit == "" {
@@ -116,6 +204,48 @@ func (hi Info) Validate() error {
 	return errors.Errorf("unknown hook kind %q", hi.Kind)
 }
 
+// Clone returns an independent copy of hi, deep-copying Members so that
+// mutating the result, or the map values in it, does not affect hi.
+func (hi Info) Clone() Info {
+	if hi.Members == nil {
+		return hi
+	}
+	members := make(map[string]*int64, len(hi.Members))
+	for name, version := range hi.Members {
+		if version == nil {
+			members[name] = nil
+			continue
+		}
+		v := *version
+		members[name] = &v
+	}
+	hi.Members = members
+	return hi
+}
+
+// Equal reports whether hi and other describe the same hook, doing a
+// proper recursive comparison of the Members map (whose *int64 values
+// make Info uncomparable with ==) rather than comparing pointers.
+func (hi Info) Equal(other Info) bool {
+	if len(hi.Members) != len(other.Members) {
+		return false
+	}
+	for name, version := range hi.Members {
+		otherVersion, found := other.Members[name]
+		if !found {
+			return false
+		}
+		if (version == nil) != (otherVersion == nil) {
+			return false
+		}
+		if version != nil && *version != *otherVersion {
+			return false
+		}
+	}
+	hi.Members, other.Members = nil, nil
+	return reflect.DeepEqual(hi, other)
+}
+
 // Committer is an interface that may be used to convey the fact that the
 // specified hook has been successfully executed, and committed.
 type Committer interface {
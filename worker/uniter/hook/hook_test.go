@@ -78,6 +78,16 @@ var validateTests = []struct {
 	{hook.Info{Kind: hooks.StorageDetaching, StorageId: "data/0"}, ""},
 	{hook.Info{Kind: hooks.PebbleReady, WorkloadName: "gitlab"}, ""},
 	{hook.Info{Kind: hooks.PreSeriesUpgrade, SeriesUpgradeTarget: "focal"}, ""},
+	{
+		hook.Info{Kind: hooks.ConfigChanged, RelationId: 1},
+		`"config-changed" hook does not accept relation id or remote unit`,
+	}, {
+		hook.Info{Kind: hooks.Install, RemoteUnit: "x/0"},
+		`"install" hook does not accept relation id or remote unit`,
+	}, {
+		hook.Info{Kind: hooks.RelationBroken, RemoteUnit: "x/0"},
+		`"relation-broken" hook does not accept a remote unit`,
+	},
 }
 
 func (s *InfoSuite) TestValidate(c *gc.C) {
@@ -91,3 +101,188 @@ func (s *InfoSuite) TestValidate(c *gc.C) {
 		}
 	}
 }
+
+var fullNameTests = []struct {
+	info     hook.Info
+	relation string
+	expect   string
+}{
+	{hook.Info{Kind: hooks.Install}, "", "install"},
+	{hook.Info{Kind: hooks.Start}, "", "start"},
+	{hook.Info{Kind: hooks.ConfigChanged}, "", "config-changed"},
+	{hook.Info{Kind: hooks.UpgradeCharm}, "", "upgrade-charm"},
+	{hook.Info{Kind: hooks.Stop}, "", "stop"},
+	{hook.Info{Kind: hooks.Remove}, "", "remove"},
+	{hook.Info{Kind: hooks.CollectMetrics}, "", "collect-metrics"},
+	{hook.Info{Kind: hooks.UpdateStatus}, "", "update-status"},
+	{hook.Info{Kind: hooks.LeaderElected}, "", "leader-elected"},
+	{
+		hook.Info{Kind: hooks.RelationJoined, RelationId: 1, RemoteUnit: "mysql/0"},
+		"db",
+		"db-relation-joined for mysql/0",
+	}, {
+		hook.Info{Kind: hooks.RelationChanged, RelationId: 1, RemoteUnit: "mysql/0"},
+		"db",
+		"db-relation-changed for mysql/0",
+	}, {
+		hook.Info{Kind: hooks.RelationDeparted, RelationId: 1, RemoteUnit: "mysql/0"},
+		"db",
+		"db-relation-departed for mysql/0",
+	}, {
+		hook.Info{Kind: hooks.RelationCreated, RelationId: 1},
+		"db",
+		"db-relation-created",
+	}, {
+		hook.Info{Kind: hooks.RelationBroken, RelationId: 1},
+		"db",
+		"db-relation-broken",
+	},
+}
+
+func (s *InfoSuite) TestFullName(c *gc.C) {
+	for i, t := range fullNameTests {
+		c.Logf("test %d", i)
+		c.Assert(t.info.FullName(t.relation), gc.Equals, t.expect)
+	}
+}
+
+var parseKindTests = []struct {
+	name     string
+	kind     hooks.Kind
+	relation string
+	ok       bool
+}{
+	{"install", hooks.Install, "", true},
+	{"start", hooks.Start, "", true},
+	{"config-changed", hooks.ConfigChanged, "", true},
+	{"upgrade-charm", hooks.UpgradeCharm, "", true},
+	{"db-relation-created", hooks.RelationCreated, "db", true},
+	{"db-relation-joined", hooks.RelationJoined, "db", true},
+	{"db-relation-changed", hooks.RelationChanged, "db", true},
+	{"db-relation-departed", hooks.RelationDeparted, "db", true},
+	{"db-relation-broken", hooks.RelationBroken, "db", true},
+	{"cluster-peer-relation-joined", hooks.RelationJoined, "cluster-peer", true},
+	{"relation-joined", "", "", false},
+	{"bogus", "", "", false},
+	{"", "", "", false},
+}
+
+func (s *InfoSuite) TestParseKind(c *gc.C) {
+	for i, t := range parseKindTests {
+		c.Logf("test %d: %q", i, t.name)
+		kind, relationName, ok := hook.ParseKind(t.name)
+		c.Check(ok, gc.Equals, t.ok)
+		if t.ok {
+			c.Check(kind, gc.Equals, t.kind)
+			c.Check(relationName, gc.Equals, t.relation)
+		}
+	}
+}
+
+func (s *InfoSuite) TestString(c *gc.C) {
+	c.Assert(hook.Info{Kind: hooks.Install}.String(), gc.Equals, "install")
+	c.Assert(
+		hook.Info{Kind: hooks.RelationJoined, RelationId: 3, RemoteUnit: "mysql/0"}.String(),
+		gc.Equals,
+		"3-relation-joined for mysql/0",
+	)
+}
+
+var allKinds = []hooks.Kind{
+	hooks.Install,
+	hooks.Start,
+	hooks.ConfigChanged,
+	hooks.UpgradeCharm,
+	hooks.Stop,
+	hooks.Remove,
+	hooks.Action,
+	hooks.CollectMetrics,
+	hooks.MeterStatusChanged,
+	hooks.LeaderElected,
+	hooks.LeaderDeposed,
+	hooks.LeaderSettingsChanged,
+	hooks.UpdateStatus,
+	hooks.PreSeriesUpgrade,
+	hooks.PostSeriesUpgrade,
+	hooks.SecretChanged,
+	hooks.SecretExpired,
+	hooks.SecretRemove,
+	hooks.SecretRotate,
+	hooks.RelationCreated,
+	hooks.RelationJoined,
+	hooks.RelationChanged,
+	hooks.RelationDeparted,
+	hooks.RelationBroken,
+	hooks.StorageAttached,
+	hooks.StorageDetaching,
+	hooks.PebbleReady,
+}
+
+var relationKinds = map[hooks.Kind]bool{
+	hooks.RelationCreated:  true,
+	hooks.RelationJoined:   true,
+	hooks.RelationChanged:  true,
+	hooks.RelationDeparted: true,
+	hooks.RelationBroken:   true,
+}
+
+func (s *InfoSuite) TestIsRelationHook(c *gc.C) {
+	for _, kind := range allKinds {
+		c.Check(hook.IsRelationHook(kind), gc.Equals, relationKinds[kind], gc.Commentf("kind %q", kind))
+	}
+}
+
+func int64ptr(v int64) *int64 {
+	return &v
+}
+
+func (s *InfoSuite) TestEqualSameMembers(c *gc.C) {
+	a := hook.Info{Kind: hooks.RelationChanged, Members: map[string]*int64{
+		"mysql/0": int64ptr(3),
+		"mysql/1": nil,
+	}}
+	b := hook.Info{Kind: hooks.RelationChanged, Members: map[string]*int64{
+		"mysql/0": int64ptr(3),
+		"mysql/1": nil,
+	}}
+	c.Assert(a.Equal(b), jc.IsTrue)
+}
+
+func (s *InfoSuite) TestEqualDifferentMemberVersion(c *gc.C) {
+	a := hook.Info{Kind: hooks.RelationChanged, Members: map[string]*int64{"mysql/0": int64ptr(3)}}
+	b := hook.Info{Kind: hooks.RelationChanged, Members: map[string]*int64{"mysql/0": int64ptr(4)}}
+	c.Assert(a.Equal(b), jc.IsFalse)
+}
+
+func (s *InfoSuite) TestEqualDifferentMembersKnown(c *gc.C) {
+	a := hook.Info{Kind: hooks.RelationChanged, Members: map[string]*int64{"mysql/0": nil}}
+	b := hook.Info{Kind: hooks.RelationChanged, Members: map[string]*int64{"mysql/0": int64ptr(0)}}
+	c.Assert(a.Equal(b), jc.IsFalse)
+}
+
+func (s *InfoSuite) TestEqualDifferentFieldOutsideMembers(c *gc.C) {
+	a := hook.Info{Kind: hooks.Install}
+	b := hook.Info{Kind: hooks.Start}
+	c.Assert(a.Equal(b), jc.IsFalse)
+}
+
+func (s *InfoSuite) TestCloneIsIndependentOfOriginal(c *gc.C) {
+	orig := hook.Info{Kind: hooks.RelationChanged, Members: map[string]*int64{
+		"mysql/0": int64ptr(3),
+		"mysql/1": nil,
+	}}
+	cloned := orig.Clone()
+	c.Assert(cloned.Equal(orig), jc.IsTrue)
+
+	*cloned.Members["mysql/0"] = 99
+	cloned.Members["mysql/2"] = int64ptr(1)
+	c.Assert(*orig.Members["mysql/0"], gc.Equals, int64(3))
+	c.Assert(orig.Members, gc.HasLen, 2)
+}
+
+func (s *InfoSuite) TestCloneNilMembers(c *gc.C) {
+	orig := hook.Info{Kind: hooks.Install}
+	cloned := orig.Clone()
+	c.Assert(cloned.Members, gc.IsNil)
+	c.Assert(cloned.Equal(orig), jc.IsTrue)
+}
@@ -0,0 +1,285 @@
+// Copyright 2012, 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/errors"
+	goyaml "gopkg.in/yaml.v2"
+)
+
+// ErrNoStateFile is returned by StateFile.Read when the underlying file
+// does not exist.
+var ErrNoStateFile = errors.New("hook state file does not exist")
+
+// InvalidStateError is returned by StateFile.Read when the underlying
+// file exists but cannot be interpreted as hook state, either because
+// it is not valid YAML or because it does not describe a valid Info.
+// Callers can use this to distinguish a corrupt state file (which might
+// be recoverable, e.g. by discarding it) from a missing one.
+type InvalidStateError struct {
+	Path  string
+	Cause error
+}
+
+// Error is part of the error interface.
+func (e *InvalidStateError) Error() string {
+	return fmt.Sprintf("invalid hook state at %s: %v", e.Path, e.Cause)
+}
+
+// Unwrap allows errors.Is and errors.As to see through to Cause.
+func (e *InvalidStateError) Unwrap() error {
+	return e.Cause
+}
+
+// StateFile holds the disk state for a hook, persisted between uniter
+// restarts so that an interrupted hook can be identified and recovered
+// from.
+type StateFile struct {
+	path string
+}
+
+// NewStateFile returns a new StateFile using path.
+func NewStateFile(path string) *StateFile {
+	return &StateFile{path}
+}
+
+// Read reads a hook Info from the file. If the file does not exist it
+// returns ErrNoStateFile.
+func (f *StateFile) Read() (*Info, error) {
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return nil, ErrNoStateFile
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var st Info
+	if err := goyaml.Unmarshal(data, &st); err != nil {
+		return nil, &InvalidStateError{Path: f.path, Cause: err}
+	}
+	if err := st.Validate(); err != nil {
+		return nil, &InvalidStateError{Path: f.path, Cause: err}
+	}
+	if st.Members == nil && st.RemoteUnit != "" {
+		// A state file written before Members existed; synthesize the
+		// one-entry map the legacy remote-unit/change-version fields
+		// describe, so callers see a consistent Members view regardless
+		// of which uniter version wrote the file.
+		changeVersion := st.ChangeVersion
+		st.Members = map[string]*int64{st.RemoteUnit: &changeVersion}
+	}
+	return &st, nil
+}
+
+// Write stores the supplied hook Info to the file, replacing any
+// existing content. It returns an error without writing anything if
+// info is not valid.
+func (f *StateFile) Write(info *Info) error {
+	if err := info.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	data, err := goyaml.Marshal(info)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return atomicWrite(f.path, data)
+}
+
+// Clear removes the persisted state, along with any stale ".preparing"
+// sibling left behind by an interrupted Write. A missing file is not
+// treated as an error.
+func (f *StateFile) Clear() error {
+	if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+		return errors.Trace(err)
+	}
+	if err := os.Remove(f.path + ".preparing"); err != nil && !os.IsNotExist(err) {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// atomicWrite writes data to a ".preparing" sibling of path and then
+// renames it into place, so that a reader never observes a partially
+// written file. Both the temp file and the containing directory are
+// fsynced so the write survives a crash immediately after the rename;
+// directory fsync errors are ignored on platforms that don't support it.
+func atomicWrite(path string, data []byte) error {
+	tmp := path + ".preparing"
+	if err := writePreparing(tmp, data); err != nil {
+		return errors.Trace(err)
+	}
+	if err := commitPrepared(tmp, path); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// writePreparing writes data to tmp, fsyncing it so the content survives a
+// crash before it is later renamed into place by commitPrepared. On
+// failure tmp is removed.
+func writePreparing(tmp string, data []byte) error {
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return errors.Trace(err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return errors.Trace(err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// commitPrepared renames a tmp file written by writePreparing into place at
+// path, and fsyncs the containing directory so the rename survives a crash.
+func commitPrepared(tmp, path string) error {
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return errors.Trace(err)
+	}
+	if dirFile, err := os.Open(filepath.Dir(path)); err == nil {
+		// Best effort: some platforms/filesystems don't support
+		// fsyncing a directory, so ignore any error here.
+		_ = dirFile.Sync()
+		_ = dirFile.Close()
+	}
+	return nil
+}
+
+// MigrateStateFile upgrades the hook state file at path, written by an
+// older uniter version, to the current serialization by filling in
+// defaults for fields those versions didn't write, such as StartedAt.
+// A file that already has those fields is left untouched, so calling
+// MigrateStateFile more than once on the same path is safe. A missing
+// file returns ErrNoStateFile.
+func MigrateStateFile(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ErrNoStateFile
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+
+	var raw map[string]interface{}
+	if err := goyaml.Unmarshal(data, &raw); err != nil {
+		return &InvalidStateError{Path: path, Cause: err}
+	}
+	if _, ok := raw["started-at"]; ok {
+		// Already in the current format.
+		return nil
+	}
+
+	var info Info
+	if err := goyaml.Unmarshal(data, &info); err != nil {
+		return &InvalidStateError{Path: path, Cause: err}
+	}
+	if err := info.Validate(); err != nil {
+		return &InvalidStateError{Path: path, Cause: err}
+	}
+	info.StartedAt = time.Now().UTC()
+
+	newData, err := goyaml.Marshal(&info)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return atomicWrite(path, newData)
+}
+
+// WriteAll atomically writes every supplied hook Info to its StateFile,
+// with all-or-nothing semantics across the whole batch. Each Info is first
+// written to its StateFile's ".preparing" sibling; only once every one of
+// those writes has succeeded are they renamed into place. If any write, or
+// any rename, fails, every real state file this call has already renamed
+// is restored to the content it held before WriteAll was called, any
+// still-pending ".preparing" files are removed, and none of the real
+// state files are left changed.
+//
+// This tree's StateFile only ever persists an Info (there is no separate
+// Status to bundle alongside it), so WriteAll takes Infos directly rather
+// than a wrapper pair type.
+func WriteAll(states map[*StateFile]*Info) error {
+	type prepared struct {
+		file *StateFile
+		tmp  string
+	}
+	var done []prepared
+	rollback := func() {
+		for _, p := range done {
+			os.Remove(p.tmp)
+		}
+	}
+
+	for file, info := range states {
+		if err := info.Validate(); err != nil {
+			rollback()
+			return errors.Trace(err)
+		}
+		data, err := goyaml.Marshal(info)
+		if err != nil {
+			rollback()
+			return errors.Trace(err)
+		}
+		tmp := file.path + ".preparing"
+		if err := writePreparing(tmp, data); err != nil {
+			rollback()
+			return errors.Trace(err)
+		}
+		done = append(done, prepared{file: file, tmp: tmp})
+	}
+
+	// committed records, for every rename already performed below, what
+	// its destination held beforehand, so a later failure can put every
+	// real file back exactly as WriteAll found it rather than leaving
+	// the batch half-applied.
+	type committed struct {
+		path    string
+		backup  []byte
+		existed bool
+	}
+	var committedFiles []committed
+	restoreCommitted := func() {
+		for i := len(committedFiles) - 1; i >= 0; i-- {
+			c := committedFiles[i]
+			if c.existed {
+				os.WriteFile(c.path, c.backup, 0644)
+			} else {
+				os.Remove(c.path)
+			}
+		}
+	}
+
+	for i, p := range done {
+		backup, err := os.ReadFile(p.file.path)
+		existed := err == nil
+		if err != nil && !os.IsNotExist(err) {
+			restoreCommitted()
+			for _, rest := range done[i:] {
+				os.Remove(rest.tmp)
+			}
+			return errors.Trace(err)
+		}
+		if err := commitPrepared(p.tmp, p.file.path); err != nil {
+			restoreCommitted()
+			for _, rest := range done[i+1:] {
+				os.Remove(rest.tmp)
+			}
+			return errors.Trace(err)
+		}
+		committedFiles = append(committedFiles, committed{path: p.file.path, backup: backup, existed: existed})
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+// Copyright 2012, 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hook_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/charm/v9/hooks"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/hook"
+)
+
+type StateFileSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&StateFileSuite{})
+
+func (s *StateFileSuite) TestReadNoFile(c *gc.C) {
+	f := hook.NewStateFile(filepath.Join(c.MkDir(), "hookstate"))
+	_, err := f.Read()
+	c.Assert(err, gc.Equals, hook.ErrNoStateFile)
+}
+
+func (s *StateFileSuite) TestWriteThenRead(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "hookstate")
+	f := hook.NewStateFile(path)
+	info := &hook.Info{Kind: hooks.ConfigChanged}
+	err := f.Write(info)
+	c.Assert(err, jc.ErrorIsNil)
+
+	got, err := f.Read()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, info)
+}
+
+func (s *StateFileSuite) TestWriteThenReadStorageHook(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "hookstate")
+	f := hook.NewStateFile(path)
+	info := &hook.Info{Kind: hooks.StorageAttached, StorageId: "data/0"}
+	err := f.Write(info)
+	c.Assert(err, jc.ErrorIsNil)
+
+	got, err := f.Read()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, info)
+}
+
+func (s *StateFileSuite) TestWriteThenReadMembersMixedKnownAndUnknownVersions(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "hookstate")
+	f := hook.NewStateFile(path)
+	known := int64(3)
+	info := &hook.Info{
+		Kind:              hooks.RelationChanged,
+		RelationId:        1,
+		RemoteUnit:        "mysql/0",
+		RemoteApplication: "mysql",
+		ChangeVersion:     3,
+		Members: map[string]*int64{
+			"mysql/0": &known,
+			"mysql/1": nil,
+		},
+	}
+	err := f.Write(info)
+	c.Assert(err, jc.ErrorIsNil)
+
+	got, err := f.Read()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, info)
+}
+
+func (s *StateFileSuite) TestReadSynthesizesMembersFromLegacyFields(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "hookstate")
+	// A state file written before Members existed: only the legacy
+	// remote-unit/change-version pair is present, with no
+	// members-versions key at all.
+	data := "kind: relation-changed\nrelation-id: 1\nremote-unit: mysql/0\nremote-application: mysql\nchange-version: 5\n"
+	err := os.WriteFile(path, []byte(data), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	f := hook.NewStateFile(path)
+	got, err := f.Read()
+	c.Assert(err, jc.ErrorIsNil)
+	version := int64(5)
+	c.Assert(got.Members, jc.DeepEquals, map[string]*int64{"mysql/0": &version})
+}
+
+func (s *StateFileSuite) TestWriteOverwritesPreviousState(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "hookstate")
+	f := hook.NewStateFile(path)
+	c.Assert(f.Write(&hook.Info{Kind: hooks.Install}), jc.ErrorIsNil)
+	final := &hook.Info{Kind: hooks.Start}
+	c.Assert(f.Write(final), jc.ErrorIsNil)
+
+	got, err := f.Read()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, final)
+}
+
+func (s *StateFileSuite) TestWriteRejectsInvalidInfo(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "hookstate")
+	f := hook.NewStateFile(path)
+	err := f.Write(&hook.Info{Kind: hooks.ConfigChanged, RelationId: 1})
+	c.Assert(err, gc.ErrorMatches, `"config-changed" hook does not accept relation id or remote unit`)
+
+	_, err = f.Read()
+	c.Assert(err, gc.Equals, hook.ErrNoStateFile)
+}
+
+func (s *StateFileSuite) TestClear(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "hookstate")
+	f := hook.NewStateFile(path)
+	err := f.Write(&hook.Info{Kind: hooks.ConfigChanged})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Leave behind a stale .preparing sibling, as an interrupted Write
+	// would.
+	err = os.WriteFile(path+".preparing", []byte("kind: stale\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = f.Clear()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = f.Read()
+	c.Assert(err, gc.Equals, hook.ErrNoStateFile)
+	_, err = os.Stat(path + ".preparing")
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *StateFileSuite) TestClearNoFile(c *gc.C) {
+	f := hook.NewStateFile(filepath.Join(c.MkDir(), "hookstate"))
+	c.Assert(f.Clear(), jc.ErrorIsNil)
+}
+
+func (s *StateFileSuite) TestReadTruncatedYAML(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "hookstate")
+	err := os.WriteFile(path, []byte("kind: [not valid"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	f := hook.NewStateFile(path)
+	_, err = f.Read()
+	c.Assert(err, gc.FitsTypeOf, &hook.InvalidStateError{})
+}
+
+func (s *StateFileSuite) TestReadUnknownKind(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "hookstate")
+	err := os.WriteFile(path, []byte("kind: splat\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	f := hook.NewStateFile(path)
+	_, err = f.Read()
+	c.Assert(err, gc.FitsTypeOf, &hook.InvalidStateError{})
+	c.Assert(err, gc.ErrorMatches, `invalid hook state at .*: unknown hook kind "splat"`)
+}
+
+func (s *StateFileSuite) TestMigrateStateFileLegacyFormat(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "hookstate")
+	// A legacy state file, as written before StartedAt existed.
+	err := os.WriteFile(path, []byte("kind: config-changed\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = hook.MigrateStateFile(path)
+	c.Assert(err, jc.ErrorIsNil)
+
+	f := hook.NewStateFile(path)
+	got, err := f.Read()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got.Kind, gc.Equals, hooks.ConfigChanged)
+	c.Assert(got.StartedAt.IsZero(), jc.IsFalse)
+}
+
+func (s *StateFileSuite) TestMigrateStateFileIsIdempotent(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "hookstate")
+	err := os.WriteFile(path, []byte("kind: config-changed\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(hook.MigrateStateFile(path), jc.ErrorIsNil)
+	f := hook.NewStateFile(path)
+	migrated, err := f.Read()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Migrating again must leave an already-current file untouched.
+	c.Assert(hook.MigrateStateFile(path), jc.ErrorIsNil)
+	again, err := f.Read()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(again, jc.DeepEquals, migrated)
+}
+
+func (s *StateFileSuite) TestMigrateStateFileCurrentFormatUntouched(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "hookstate")
+	f := hook.NewStateFile(path)
+	info := &hook.Info{Kind: hooks.ConfigChanged, StartedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c.Assert(f.Write(info), jc.ErrorIsNil)
+
+	err := hook.MigrateStateFile(path)
+	c.Assert(err, jc.ErrorIsNil)
+
+	got, err := f.Read()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, info)
+}
+
+func (s *StateFileSuite) TestMigrateStateFileNoFile(c *gc.C) {
+	err := hook.MigrateStateFile(filepath.Join(c.MkDir(), "hookstate"))
+	c.Assert(err, gc.Equals, hook.ErrNoStateFile)
+}
+
+func (s *StateFileSuite) TestWriteAllSuccess(c *gc.C) {
+	dir := c.MkDir()
+	fileA := hook.NewStateFile(filepath.Join(dir, "a"))
+	fileB := hook.NewStateFile(filepath.Join(dir, "b"))
+	infoA := &hook.Info{Kind: hooks.Install}
+	infoB := &hook.Info{Kind: hooks.ConfigChanged}
+
+	err := hook.WriteAll(map[*hook.StateFile]*hook.Info{
+		fileA: infoA,
+		fileB: infoB,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	gotA, err := fileA.Read()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(gotA, jc.DeepEquals, infoA)
+
+	gotB, err := fileB.Read()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(gotB, jc.DeepEquals, infoB)
+}
+
+func (s *StateFileSuite) TestWriteAllMidBatchFailureLeavesNoFilesModified(c *gc.C) {
+	dir := c.MkDir()
+	pathA := filepath.Join(dir, "a")
+	pathB := filepath.Join(dir, "b")
+	fileA := hook.NewStateFile(pathA)
+	fileB := hook.NewStateFile(pathB)
+
+	// fileA already holds prior state, which a failed WriteAll must leave
+	// untouched; fileB has never been written.
+	priorA := &hook.Info{Kind: hooks.Start}
+	c.Assert(fileA.Write(priorA), jc.ErrorIsNil)
+
+	err := hook.WriteAll(map[*hook.StateFile]*hook.Info{
+		fileA: {Kind: hooks.ConfigChanged},
+		fileB: {Kind: hooks.ConfigChanged, RelationId: 1}, // invalid: not a relation hook
+	})
+	c.Assert(err, gc.ErrorMatches, `"config-changed" hook does not accept relation id or remote unit`)
+
+	gotA, err := fileA.Read()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(gotA, jc.DeepEquals, priorA)
+
+	_, err = fileB.Read()
+	c.Assert(err, gc.Equals, hook.ErrNoStateFile)
+
+	_, err = os.Stat(pathA + ".preparing")
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+	_, err = os.Stat(pathB + ".preparing")
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *StateFileSuite) TestWriteAllMidRenameFailureRestoresAlreadyRenamedFiles(c *gc.C) {
+	dir := c.MkDir()
+	pathA := filepath.Join(dir, "a")
+	pathC := filepath.Join(dir, "c")
+	fileA := hook.NewStateFile(pathA)
+	fileC := hook.NewStateFile(pathC)
+
+	// fileA already holds prior state. fileC's path is a directory, not a
+	// regular file, so committing it always fails (reading its prior
+	// content as a backup fails before any rename is attempted): if fileA
+	// is committed first, this exercises the rollback of an
+	// already-renamed file.
+	priorA := &hook.Info{Kind: hooks.Start}
+	c.Assert(fileA.Write(priorA), jc.ErrorIsNil)
+	c.Assert(os.Mkdir(pathC, 0755), jc.ErrorIsNil)
+
+	err := hook.WriteAll(map[*hook.StateFile]*hook.Info{
+		fileA: {Kind: hooks.ConfigChanged},
+		fileC: {Kind: hooks.Install},
+	})
+	c.Assert(err, gc.NotNil)
+
+	gotA, err := fileA.Read()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(gotA, jc.DeepEquals, priorA)
+
+	info, err := os.Stat(pathC)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.IsDir(), jc.IsTrue)
+
+	_, err = os.Stat(pathA + ".preparing")
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+	_, err = os.Stat(pathC + ".preparing")
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
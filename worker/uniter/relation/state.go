@@ -158,8 +158,10 @@ func (s *State) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
-// copy returns an independent copy of the state.
-func (s *State) copy() *State {
+// Clone returns an independent copy of the state, deep-copying the
+// Members and ApplicationMembers maps so that mutating the result does
+// not affect the original.
+func (s *State) Clone() *State {
 	stCopy := NewState(s.RelationId)
 	stCopy.ChangedPending = s.ChangedPending
 	for m, v := range s.Members {
@@ -170,3 +172,32 @@ func (s *State) copy() *State {
 	}
 	return stCopy
 }
+
+// Equal reports whether s and other describe the same relation state,
+// doing a proper recursive comparison of the Members and
+// ApplicationMembers maps rather than relying on a shallow comparison
+// that would always treat them as different.
+func (s *State) Equal(other *State) bool {
+	if s == nil || other == nil {
+		return s == other
+	}
+	if s.RelationId != other.RelationId || s.ChangedPending != other.ChangedPending {
+		return false
+	}
+	return equalMemberMaps(s.Members, other.Members) &&
+		equalMemberMaps(s.ApplicationMembers, other.ApplicationMembers)
+}
+
+// equalMemberMaps reports whether a and b contain the same set of keys
+// mapped to the same change versions.
+func equalMemberMaps(a, b map[string]int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for m, v := range a {
+		if bv, ok := b[m]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
@@ -361,3 +361,30 @@ func (s *stateSuite) TestStateValidateErrorBrokenJoined(c *gc.C) {
 	expect := fmt.Sprintf(`inappropriate %q for %q: relation is broken and cannot be changed further`, hiInfo.Kind, hiInfo.RemoteUnit)
 	c.Assert(err, gc.ErrorMatches, expect)
 }
+
+func (s *stateSuite) TestStateEqual(c *gc.C) {
+	st := s.setupTestState()
+	other := s.setupTestState()
+	c.Assert(st.Equal(other), jc.IsTrue)
+}
+
+func (s *stateSuite) TestStateEqualDiffersByMember(c *gc.C) {
+	st := s.setupTestState()
+	other := s.setupTestState()
+	other.Members["foo/2"] = 1
+
+	c.Assert(st.Equal(other), jc.IsFalse)
+}
+
+func (s *stateSuite) TestStateCloneIndependence(c *gc.C) {
+	st := s.setupTestState()
+	clone := st.Clone()
+	c.Assert(clone.Equal(st), jc.IsTrue)
+
+	clone.Members["foo/1"] = 99
+	delete(clone.ApplicationMembers, "foo")
+
+	c.Assert(st.Members["foo/1"], gc.Equals, int64(0))
+	c.Assert(st.ApplicationMembers, gc.HasLen, 1)
+	c.Assert(clone.Equal(st), jc.IsFalse)
+}
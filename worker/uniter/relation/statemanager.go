@@ -35,7 +35,7 @@ func (m *stateManager) Relation(id int) (*State, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if s, ok := m.relationState[id]; ok {
-		return s.copy(), nil
+		return s.Clone(), nil
 	}
 	return nil, errors.NotFoundf("relation %d", id)
 }